@@ -0,0 +1,205 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// inventoryHost is a device in an Ansible inventory.
+type inventoryHost struct {
+	name string
+	vars [][2]string
+}
+
+// inventory has the devices as hosts in a group per site and a
+// group per device type. Host vars are only given in the site group.
+// Site groups start with site_ so that a site can not be named
+// like a type group.
+type inventory struct {
+	hosts  []*inventoryHost
+	sites  map[string][]*inventoryHost
+	types  map[string][]*inventoryHost
+	groups []string
+}
+
+func buildInventory(devices []*Device) *inventory {
+	inv := &inventory{
+		sites: make(map[string][]*inventoryHost),
+		types: make(map[string][]*inventoryHost),
+	}
+	used := make(map[string]bool)
+	for _, d := range devices {
+		h := &inventoryHost{name: hostName(d, used)}
+		if d.IP != "" {
+			h.vars = append(h.vars, [2]string{"ansible_host", d.IP})
+		}
+		h.vars = append(h.vars,
+			[2]string{"mac", d.Mac},
+			[2]string{"model", d.Model},
+			[2]string{"firmware", d.Version},
+		)
+		if d.Uplink != nil && d.Uplink.Mac != "" {
+			h.vars = append(h.vars,
+				[2]string{"uplink_mac", d.Uplink.Mac},
+				[2]string{"uplink_name", d.Uplink.Name},
+				[2]string{"uplink_port", d.Uplink.Port},
+			)
+		}
+		inv.hosts = append(inv.hosts, h)
+		site := "site_" + groupName(d.Site)
+		inv.sites[site] = append(inv.sites[site], h)
+		kind := groupName(d.Type)
+		inv.types[kind] = append(inv.types[kind], h)
+	}
+	for name := range inv.sites {
+		inv.groups = append(inv.groups, name)
+	}
+	for name := range inv.types {
+		inv.groups = append(inv.groups, name)
+	}
+	sort.Strings(inv.groups)
+	return inv
+}
+
+// hostName makes the name of the device, or the MAC if it has none,
+// a valid host name of letters, digits, dots, underscores and dashes.
+// A name that is already used gets the MAC added.
+func hostName(d *Device, used map[string]bool) string {
+	name := strings.Trim(strings.Map(func(r rune) rune {
+		if r == '.' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '-'
+	}, d.Displayname()), "-")
+	for strings.Contains(name, "--") {
+		name = strings.ReplaceAll(name, "--", "-")
+	}
+	mac := strings.ReplaceAll(d.Mac, ":", "")
+	if name == "" {
+		name = mac
+	}
+	if used[name] {
+		name += "-" + mac
+	}
+	used[name] = true
+	return name
+}
+
+// groupName makes s a group name of lower case letters, digits
+// and underscores. Nothing left means ungrouped.
+func groupName(s string) string {
+	name := strings.Trim(strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, strings.ToLower(s)), "_")
+	for strings.Contains(name, "__") {
+		name = strings.ReplaceAll(name, "__", "_")
+	}
+	if name == "" {
+		return "ungrouped"
+	}
+	return name
+}
+
+// members returns the hosts of group and if their vars belong there.
+func (inv *inventory) members(group string) ([]*inventoryHost, bool) {
+	if hosts, ok := inv.sites[group]; ok {
+		return hosts, true
+	}
+	return inv.types[group], false
+}
+
+// writeINI outputs the inventory in the ini format.
+func (inv *inventory) writeINI(out io.Writer) error {
+	w := bufio.NewWriter(out)
+	for i, group := range inv.groups {
+		if i > 0 {
+			w.WriteString("\n")
+		}
+		fmt.Fprintf(w, "[%s]\n", group)
+		hosts, withVars := inv.members(group)
+		for _, h := range hosts {
+			w.WriteString(h.name)
+			for _, v := range h.vars {
+				if withVars && v[1] != "" {
+					fmt.Fprintf(w, " %s=%s", v[0], iniValue(v[1]))
+				}
+			}
+			w.WriteString("\n")
+		}
+	}
+	return w.Flush()
+}
+
+// iniValue quotes v if it would otherwise be split or cut.
+func iniValue(v string) string {
+	if strings.ContainsAny(v, " \t\"'=#;\\") {
+		return strconv.Quote(v)
+	}
+	return v
+}
+
+// writeYAML outputs the inventory in the yaml format with
+// every group as a child of all.
+func (inv *inventory) writeYAML(out io.Writer) error {
+	children := &yaml.Node{Kind: yaml.MappingNode}
+	for _, group := range inv.groups {
+		hosts := &yaml.Node{Kind: yaml.MappingNode}
+		members, withVars := inv.members(group)
+		for _, h := range members {
+			vars := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+			if withVars {
+				vars = &yaml.Node{Kind: yaml.MappingNode}
+				for _, v := range h.vars {
+					if v[1] != "" {
+						vars.Content = append(vars.Content, yamlString(v[0]), yamlString(v[1]))
+					}
+				}
+			}
+			hosts.Content = append(hosts.Content, yamlString(h.name), vars)
+		}
+		children.Content = append(children.Content, yamlString(group), &yaml.Node{
+			Kind:    yaml.MappingNode,
+			Content: []*yaml.Node{yamlString("hosts"), hosts},
+		})
+	}
+	doc := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+		yamlString("all"),
+		{Kind: yaml.MappingNode, Content: []*yaml.Node{yamlString("children"), children}},
+	}}
+	enc := yaml.NewEncoder(out)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// deviceAnsible returns a renderer for the devices as an Ansible
+// inventory or nil if ext is not ini, yaml or yml.
+func deviceAnsible(ext string) deviceRender {
+	switch ext {
+	case ".ini":
+		return func(out io.Writer, devices []*Device, _ []string) error {
+			return buildInventory(devices).writeINI(out)
+		}
+	case ".yaml", ".yml":
+		return func(out io.Writer, devices []*Device, _ []string) error {
+			return buildInventory(devices).writeYAML(out)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func testInventoryDevices() []*Device {
+	return []*Device{
+		{Mac: "f0:9f:c2:00:00:01", Name: "core sw", Type: "USW", Site: "Office (default)", IP: "10.0.0.2",
+			Model: "US24P250", Version: "6.5.59", Uplink: &DevicePort{Mac: "f0:9f:c2:00:00:03", Name: "Dream Machine", Port: "1"}},
+		{Mac: "f0:9f:c2:00:00:02", Name: "core sw", Type: "UAP", Site: "Warehouse (ab12cd34)", Model: "U7PG2"},
+		{Mac: "f0:9f:c2:00:00:03", Name: "Dream Machine", Type: "UDM", Site: "Office (default)", IP: "10.0.0.1"},
+		{Mac: "f0:9f:c2:00:00:04", Name: "[lab] #2", Type: "USW", Site: "USW", IP: "10.2.0.1"},
+	}
+}
+
+func Test_inventoryINI(t *testing.T) {
+	var buf bytes.Buffer
+	if err := deviceAnsible(".ini")(&buf, testInventoryDevices(), nil); err != nil {
+		t.Fatal(err)
+	}
+	want := `[site_office_default]
+core-sw ansible_host=10.0.0.2 mac=f0:9f:c2:00:00:01 model=US24P250 firmware=6.5.59 uplink_mac=f0:9f:c2:00:00:03 uplink_name="Dream Machine" uplink_port=1
+Dream-Machine ansible_host=10.0.0.1 mac=f0:9f:c2:00:00:03
+
+[site_usw]
+lab-2 ansible_host=10.2.0.1 mac=f0:9f:c2:00:00:04
+
+[site_warehouse_ab12cd34]
+core-sw-f09fc2000002 mac=f0:9f:c2:00:00:02 model=U7PG2
+
+[uap]
+core-sw-f09fc2000002
+
+[udm]
+Dream-Machine
+
+[usw]
+core-sw
+lab-2
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func Test_inventoryYAML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := deviceAnsible(".yml")(&buf, testInventoryDevices()[1:3], nil); err != nil {
+		t.Fatal(err)
+	}
+	want := `all:
+  children:
+    site_office_default:
+      hosts:
+        Dream-Machine:
+          ansible_host: 10.0.0.1
+          mac: f0:9f:c2:00:00:03
+    site_warehouse_ab12cd34:
+      hosts:
+        core-sw:
+          mac: f0:9f:c2:00:00:02
+          model: U7PG2
+    uap:
+      hosts:
+        core-sw:
+    udm:
+      hosts:
+        Dream-Machine:
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if deviceAnsible(".json") != nil {
+		t.Error("expected no inventory renderer for json")
+	}
+}
+
+func Test_groupName(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{"site", "Office (default)", "office_default"},
+		{"type", "USW", "usw"},
+		{"digit", "2nd floor", "2nd_floor"},
+		{"empty", "-", "ungrouped"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := groupName(tt.s); got != tt.want {
+				t.Errorf("groupName(%q) = %q, want %q", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_hostName(t *testing.T) {
+	tests := []struct {
+		name string
+		dev  string
+		want string
+	}{
+		{"spaces", "core  sw", "core-sw"},
+		{"ini", "[lab] #2 a=b;c", "lab-2-a-b-c"},
+		{"dots", "sw_1.lab", "sw_1.lab"},
+		{"letters", "Kök", "Kök"},
+		{"nothing left", "###", "f09fc2000001"},
+		{"mac", "", "f0-9f-c2-00-00-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Device{Mac: "f0:9f:c2:00:00:01", Name: tt.dev}
+			if got := hostName(d, map[string]bool{}); got != tt.want {
+				t.Errorf("hostName(%q) = %q, want %q", tt.dev, got, tt.want)
+			}
+		})
+	}
+}
@@ -5,8 +5,6 @@
 package main
 
 import (
-	"encoding/csv"
-	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
@@ -14,7 +12,6 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
-	"text/tabwriter"
 	"time"
 
 	"github.com/unpoller/unifi"
@@ -37,46 +34,52 @@ const (
 )
 
 var (
-	clientsCmd    = flag.NewFlagSet("clients", flag.ExitOnError)
-	sortFlag      = clientsCmd.Bool("sort", false, "sort my MAC")
-	outputFlag    = clientsCmd.String("output", "", "filename to output to. [*.xlsx, *.json, *.csv]")
-	client_fields = []string{
+	clientsCmd         = flag.NewFlagSet("clients", flag.ExitOnError)
+	sortFlag           = clientsCmd.Bool("sort", false, "sort my MAC")
+	outputFlag         = clientsCmd.String("output", "", "filename to output to. [*.xlsx, *.json, *.ndjson, *.yaml, *.toml, *.csv, *.md, *.html]")
+	clientSiteFlag     = clientsCmd.String("site", "", "comma separated list of sites to include")
+	clientFieldsFlag   = clientsCmd.String("fields", "", "comma separated list of fields to output")
+	clientMergeFlag    = clientsCmd.Bool("merge", false, "update an existing xlsx output by MAC and keep other columns")
+	clientTemplateFlag = clientsCmd.String("template", "", "xlsx workbook to fill or text/template file to render instead of the output format")
+	clientAnchorFlag   = clientsCmd.String("anchor", "", "defined name or cell in the template where the header is written")
+	clientRawFlag      = clientsCmd.Bool("raw", false, "output json with every field from the controller")
+	clientKeyFlag      = clientsCmd.String("key", "", "field to key yaml or toml output by, like MAC")
+	clientFirewallFlag = clientsCmd.String("firewall", "", "output address groups for pfsense, opnsense, nftables, ipset or mikrotik instead of the output format")
+	clientGroupFlag    = clientsCmd.String("group", "network", "what firewall address groups are made from, network or #tags in the note")
+	client_fields      = []string{
 		CLIENT_MAC, CLIENT_IP, CLIENT_HOSTNAME, CLIENT_NAME,
 		CLIENT_SITE, CLIENT_NETWORK, CLIENT_SWITCH, CLIENT_SWPORT,
 		CLIENT_AP, CLIENT_RSSI, CLIENT_LASTSEEN, CLIENT_NOTE,
 	}
+	clientStyle = sheetStyle{dates: map[string]bool{CLIENT_LASTSEEN: true}, rssi: CLIENT_RSSI}
 )
 
-type clientRender func(io.Writer, []*unifi.Client, map[string]*unifi.USW, map[string]*unifi.UAP)
+// clientRender outputs hydrated clients using the selected fields.
+// A nil list of fields means the default ones.
+type clientRender func(io.Writer, []*unifi.Client, []string) error
 
 // generateClients takes a list of sites ange extracts
 // information abouts clients and outputs depending on
 // outputFlag
 func generateClients(uni *unifi.Unifi, sites []*unifi.Site) {
+	fields, err := parseFields(*clientFieldsFlag, client_fields)
+	check(err)
+
+	sites = filterSites(sites, splitList(*clientSiteFlag))
 	clients, err := uni.GetClients(sites)
 	if err != nil {
 		log.Fatalln("Error:", err)
 	}
-	fmt.Println(len(clients), "Clients connected")
+	// status goes to stderr as the output can be written to stdout
+	fmt.Fprintln(os.Stderr, len(clients), "Clients connected")
 
 	devices, err := uni.GetDevices(sites)
 	if err != nil {
 		log.Fatalln("Error:", err)
 	}
+	fmt.Fprintf(os.Stderr, "\t to %d switches and %d access points\n", len(devices.USWs), len(devices.UAPs))
 
-	// get a map of switches so that we can add information later
-	switchmap := make(map[string]*unifi.USW)
-	for _, sw := range devices.USWs {
-		switchmap[sw.Mac] = sw
-	}
-
-	// get a map of access points
-	apmap := make(map[string]*unifi.UAP)
-	for _, ap := range devices.UAPs {
-		apmap[ap.Mac] = ap
-	}
-
-	fmt.Printf("\t to %d switches and %d access points\n", len(devices.USWs), len(devices.UAPs))
+	hydrateClients(clients, devices)
 
 	if *sortFlag {
 		sort.SliceStable(clients, func(i, j int) bool {
@@ -84,31 +87,121 @@ func generateClients(uni *unifi.Unifi, sites []*unifi.Site) {
 		})
 	}
 
-	var renderer clientRender
 	ext := ".table"
 	if *outputFlag != "" {
 		ext = filepath.Ext(*outputFlag)
 	}
-	switch ext {
-	case ".xlsx":
-		renderer = clientExcel
-	case ".json":
-		renderer = clientJSON
-	case ".csv":
-		renderer = clientCsv
-	case ".table":
-		renderer = clientTable
-	default:
+	if *clientMergeFlag && ext != ".xlsx" {
+		log.Fatalln("Error: -merge needs an xlsx output")
+	}
+	if *clientMergeFlag && canMerge(*outputFlag) {
+		if fields == nil {
+			fields = client_fields
+		}
+		result, err := mergeFile(*outputFlag, fields, len(clients), clientValues(clients), clientCells(clients))
+		if err != nil {
+			log.Fatalln("Error:", err)
+		}
+		fmt.Printf("merged into %s, %s\n", *outputFlag, result)
+		return
+	}
+	renderer := getClientRender(ext)
+	switch {
+	case filepath.Ext(*clientTemplateFlag) == ".xlsx":
+		if ext != ".xlsx" {
+			log.Fatalln("Error: an xlsx template needs an xlsx output")
+		}
+		renderer = func(out io.Writer, clients []*unifi.Client, fields []string) error {
+			if fields == nil {
+				fields = client_fields
+			}
+			return writeTemplate(out, *clientTemplateFlag, *clientAnchorFlag, fields, len(clients), clientCells(clients), clientStyle)
+		}
+	case *clientTemplateFlag != "":
+		renderer = func(out io.Writer, clients []*unifi.Client, fields []string) error {
+			if fields == nil {
+				fields = client_fields
+			}
+			return writeTextTemplate(out, *clientTemplateFlag, fields, len(clients), clientValues(clients))
+		}
+	}
+	if *clientFirewallFlag != "" {
+		if renderer = clientFirewall(*clientFirewallFlag, *clientGroupFlag); renderer == nil {
+			log.Fatalln("Error: -firewall takes pfsense, opnsense, nftables, ipset or mikrotik")
+		}
+	}
+	if renderer == nil {
 		log.Fatalf("unsupported extension for %s", *outputFlag)
 	}
+	if *clientRawFlag {
+		if renderer = clientRaw(ext); renderer == nil {
+			log.Fatalln("Error: -raw needs json or ndjson output")
+		}
+	}
+	if *clientKeyFlag != "" {
+		key, err := parseFields(*clientKeyFlag, client_fields)
+		check(err)
+		if len(key) != 1 {
+			log.Fatalln("Error: -key takes a single field")
+		}
+		if renderer = clientKeyed(ext, key[0]); renderer == nil {
+			log.Fatalln("Error: -key needs yaml or toml output")
+		}
+	}
 	if *outputFlag != "" {
 		f := mustCreateFile(*outputFlag)
 		defer f.Close()
-		renderer(f, clients, switchmap, apmap)
+		check(renderer(f, clients, fields))
 	} else {
-		renderer(os.Stdout, clients, switchmap, apmap)
+		check(renderer(os.Stdout, clients, fields))
+	}
+
+}
+
+// getClientRender returns the renderer for a file extension
+// or nil if the extension is not supported.
+func getClientRender(ext string) clientRender {
+	switch ext {
+	case ".xlsx":
+		return clientExcel
+	case ".json":
+		return clientJSON
+	case ".ndjson":
+		return clientNDJSON
+	case ".yaml", ".yml":
+		return clientYAML
+	case ".toml":
+		return clientTOML
+	case ".csv":
+		return clientCsv
+	case ".md":
+		return clientMarkdown
+	case ".html":
+		return clientHTML
+	case ".table":
+		return clientTable
+	}
+	return nil
+}
+
+// hydrateClients adds switch and access point names to clients
+// using the devices from the same sites.
+func hydrateClients(clients []*unifi.Client, devices *unifi.Devices) {
+	// get a map of switches so that we can add information later
+	switchmap := make(map[string]*unifi.USW)
+	for _, sw := range devices.USWs {
+		switchmap[sw.Mac] = sw
+	}
+
+	// get a map of access points
+	apmap := make(map[string]*unifi.UAP)
+	for _, ap := range devices.UAPs {
+		apmap[ap.Mac] = ap
 	}
 
+	for _, client := range clients {
+		hydrateClient(client, switchmap, apmap)
+	}
 }
 
 func hydrateClient(client *unifi.Client, switchmap map[string]*unifi.USW, apmap map[string]*unifi.UAP) {
@@ -163,97 +256,148 @@ func getClientValue(client *unifi.Client, name string) string {
 	}
 }
 
-func clientJSON(out io.Writer, clients []*unifi.Client, switchmap map[string]*unifi.USW, apmap map[string]*unifi.UAP) {
-	data, err := json.MarshalIndent(clients, "", "    ")
-	if err != nil {
-		log.Fatalf("error marshalling to JSON %v", err)
+// getClientCell returns the value of a client field typed for Excel
+// and JSON. Switch port and RSSI are nil when not connected that way.
+func getClientCell(client *unifi.Client, name string) any {
+	switch name {
+	case CLIENT_SWPORT:
+		if client.SwMac == "" {
+			return nil
+		}
+		return int(client.SwPort.Val)
+	case CLIENT_RSSI:
+		if client.ApMac == "" {
+			return nil
+		}
+		return int(client.Rssi.Val)
+	case CLIENT_LASTSEEN:
+		return time.Unix(int64(client.LastSeen.Val), 0)
+	default:
+		return getClientValue(client, name)
 	}
-	_, err = out.Write(data)
-	check(err)
 }
 
-// clientTable outputs on screen in table format
-func clientTable(out io.Writer, clients []*unifi.Client, switchmap map[string]*unifi.USW, apmap map[string]*unifi.UAP) {
-	const padding = 3
-	w := tabwriter.NewWriter(out, 10, 0, padding, ' ', 0)
-	header := ""
-	format := ""
-	for _, field := range client_fields {
-		header += field + "\t"
-		format += "%s\t"
-	}
-	format += "\n"
-	fmt.Println("header", header)
-	fmt.Println("format", format)
-	fmt.Fprintln(w, header)
-
-	values := make([]any, len(client_fields))
-	for _, client := range clients {
-		hydrateClient(client, switchmap, apmap)
-		for i, field := range client_fields {
-			values[i] = getClientValue(client, field)
+func clientJSON(out io.Writer, clients []*unifi.Client, fields []string) error {
+	if fields == nil {
+		fields = client_fields
+	}
+	return writeJSON(out, fields, len(clients), clientCells(clients))
+}
+
+func clientNDJSON(out io.Writer, clients []*unifi.Client, fields []string) error {
+	if fields == nil {
+		fields = client_fields
+	}
+	return writeNDJSON(out, fields, len(clients), clientCells(clients))
+}
+
+func clientYAML(out io.Writer, clients []*unifi.Client, fields []string) error {
+	if fields == nil {
+		fields = client_fields
+	}
+	return writeYAML(out, fields, len(clients), clientCells(clients), nil)
+}
+
+func clientTOML(out io.Writer, clients []*unifi.Client, fields []string) error {
+	if fields == nil {
+		fields = client_fields
+	}
+	return writeTOML(out, "clients", fields, len(clients), clientCells(clients), nil)
+}
+
+// clientKeyed returns a renderer for the clients as a dictionary keyed
+// by the field key or nil if ext is not yaml or toml.
+func clientKeyed(ext, key string) clientRender {
+	if ext != ".yaml" && ext != ".yml" && ext != ".toml" {
+		return nil
+	}
+	return func(out io.Writer, clients []*unifi.Client, fields []string) error {
+		if fields == nil {
+			fields = client_fields
+		}
+		keys, err := dictKeys(len(clients), key, clientValues(clients))
+		if err != nil {
+			return err
 		}
-		fmt.Fprintf(w, format, values...)
+		if ext == ".toml" {
+			return writeTOML(out, "clients", fields, len(clients), clientCells(clients), keys)
+		}
+		return writeYAML(out, fields, len(clients), clientCells(clients), keys)
 	}
-	w.Flush()
 }
 
-// clientExcel outputs to .xslx -file
-func clientExcel(out io.Writer, clients []*unifi.Client, switchmap map[string]*unifi.USW, apmap map[string]*unifi.UAP) {
-	f := excelize.NewFile()
-	// index := f.NewSheet("Sheet1")
+// clientRaw returns a renderer for the clients as they come from the
+// controller, with every field, or nil if ext is not json or ndjson.
+func clientRaw(ext string) clientRender {
+	switch ext {
+	case ".json":
+		return func(out io.Writer, clients []*unifi.Client, _ []string) error {
+			return encodeJSON(out, clients)
+		}
+	case ".ndjson":
+		return func(out io.Writer, clients []*unifi.Client, _ []string) error {
+			return encodeLines(out, len(clients), func(i int) any { return clients[i] })
+		}
+	}
+	return nil
+}
 
-	cns := getColumns(client_fields...)
+// clientValues returns a rowValue for a list of clients
+func clientValues(clients []*unifi.Client) rowValue {
+	return func(row int, field string) string {
+		return getClientValue(clients[row], field)
+	}
+}
 
-	sname := f.GetSheetName(0)
-	for _, field := range client_fields {
-		check(f.SetCellValue(sname, cns[field](1), field))
+// clientCells returns a rowCell for a list of clients
+func clientCells(clients []*unifi.Client) rowCell {
+	return func(row int, field string) any {
+		return getClientCell(clients[row], field)
 	}
+}
 
-	row := 2
-	for _, client := range clients {
-		hydrateClient(client, switchmap, apmap)
-		check(f.SetCellValue(sname, cns[CLIENT_MAC](row), client.Mac))
-		check(f.SetCellValue(sname, cns[CLIENT_IP](row), client.IP))
-		check(f.SetCellValue(sname, cns[CLIENT_HOSTNAME](row), client.Hostname))
-		check(f.SetCellValue(sname, cns[CLIENT_NAME](row), client.Name))
-		check(f.SetCellValue(sname, cns[CLIENT_SITE](row), client.SiteName))
-		check(f.SetCellValue(sname, cns[CLIENT_NETWORK](row), client.Network))
-		check(f.SetCellValue(sname, cns[CLIENT_SWITCH](row), client.SwName))
-		check(f.SetCellValue(sname, cns[CLIENT_SWPORT](row), client.SwPort.Val))
-		check(f.SetCellValue(sname, cns[CLIENT_AP](row), client.ApName))
-		check(f.SetCellValue(sname, cns[CLIENT_RSSI](row), client.Rssi))
-		check(f.SetCellValue(sname, cns[CLIENT_LASTSEEN](row), getClientValue(client, CLIENT_LASTSEEN)))
-		check(f.SetCellValue(sname, cns[CLIENT_NOTE](row), client.Note))
-
-		// fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\t\n",
-		// 	client.Mac, client.IP, client.Hostname, client.Name,
-		// 	client.SwName, &client.SwPort,
-		// 	client.ApName, client.Rssi,
-		// 	client.Note)
-		row++
-	}
-	if err := f.SaveAs(*outputFlag); err != nil {
-		log.Println(err)
+// clientTable outputs on screen in table format
+func clientTable(out io.Writer, clients []*unifi.Client, fields []string) error {
+	if fields == nil {
+		fields = client_fields
+	}
+	return writeTable(out, fields, len(clients), clientValues(clients))
+}
+
+// clientExcel outputs to .xslx -file
+func clientExcel(out io.Writer, clients []*unifi.Client, fields []string) error {
+	if fields == nil {
+		fields = client_fields
+	}
+	f := excelize.NewFile()
+	if err := writeSheet(f, f.GetSheetName(0), fields, len(clients), clientCells(clients), clientStyle); err != nil {
+		return err
 	}
+	return f.Write(out)
 }
 
-func clientCsv(out io.Writer, clients []*unifi.Client, switchmap map[string]*unifi.USW, apmap map[string]*unifi.UAP) {
+// clientSheet writes clients to the sheet sname of f
+func clientSheet(f *excelize.File, sname string, clients []*unifi.Client) error {
+	return writeSheet(f, sname, client_fields, len(clients), clientCells(clients), clientStyle)
+}
 
-	w := csv.NewWriter(out)
-	record := make([]string, len(client_fields))
-	copy(record, client_fields)
+func clientCsv(out io.Writer, clients []*unifi.Client, fields []string) error {
+	if fields == nil {
+		fields = client_fields
+	}
+	return writeCsv(out, fields, len(clients), clientValues(clients))
+}
 
-	if err := w.Write(record); err != nil {
-		log.Fatalln(err)
+func clientMarkdown(out io.Writer, clients []*unifi.Client, fields []string) error {
+	if fields == nil {
+		fields = client_fields
 	}
-	for _, client := range clients {
-		hydrateClient(client, switchmap, apmap)
-		for i, field := range client_fields {
-			record[i] = getClientValue(client, field)
-		}
+	return writeMarkdown(out, fields, len(clients), clientValues(clients))
+}
 
-		check(w.Write(record))
+func clientHTML(out io.Writer, clients []*unifi.Client, fields []string) error {
+	if fields == nil {
+		fields = client_fields
 	}
-	w.Flush()
+	return writeHTML(out, "Clients", fields, len(clients), clientValues(clients))
 }
@@ -5,18 +5,29 @@
 package main
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/unpoller/unifi"
 )
 
-func writeJSON(data any, filename string) error {
+// rowValue returns the value of field for the item at index row.
+type rowValue func(row int, field string) string
+
+func encodeJSON(out io.Writer, data any) error {
 	file, err := json.MarshalIndent(data, "", "    ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(filename, file, 0644)
+	_, err = out.Write(file)
+	return err
 }
 
 func check(err error) {
@@ -25,29 +36,233 @@ func check(err error) {
 	}
 }
 
-type colname func(row int) string
+func mustCreateFile(filename string) *os.File {
+	f, err := os.Create(filename)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	return f
+}
 
-var cols = [...]string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "J", "K", "L", "M",
-	"N", "O", "P", "Q", "R", "S", "T", "U", "V", "W", "X", "Y", "Z"}
+// splitList splits a comma separated flag value into
+// trimmed, non empty parts.
+func splitList(s string) []string {
+	var result []string
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
 
-// getColumns takes a list of names and returns a map of functions
-// that will return excel style cell keys An, Bn etc where n is the row.
-func getColumns(name ...string) map[string]colname {
-	index := make(map[string]colname)
+// parseFields validates a comma separated list of field names
+// against the available ones and returns them with the canonical
+// spelling. An empty list returns nil which means all fields.
+func parseFields(s string, available []string) ([]string, error) {
+	names := splitList(s)
+	if len(names) == 0 {
+		return nil, nil
+	}
+	fields := make([]string, 0, len(names))
+	for _, name := range names {
+		found := ""
+		for _, f := range available {
+			if strings.EqualFold(f, name) {
+				found = f
+				break
+			}
+		}
+		if found == "" {
+			return nil, fmt.Errorf("unknown field '%s', use one of %s", name, strings.Join(available, ", "))
+		}
+		fields = append(fields, found)
+	}
+	return fields, nil
+}
+
+// siteMatches reports if name refers to site either by
+// short name, description or the combined site name.
+func siteMatches(site *unifi.Site, name string) bool {
+	return strings.EqualFold(site.Name, name) ||
+		strings.EqualFold(site.Desc, name) ||
+		strings.EqualFold(site.SiteName, name)
+}
 
-	for k, v := range name {
-		col := cols[k]
-		index[v] = func(row int) string {
-			return fmt.Sprintf("%s%d", col, row)
+// filterSites returns the sites matching any of names.
+// If names is empty all sites are returned.
+func filterSites(sites []*unifi.Site, names []string) []*unifi.Site {
+	if len(names) == 0 {
+		return sites
+	}
+	var result []*unifi.Site
+	for _, site := range sites {
+		for _, name := range names {
+			if siteMatches(site, name) {
+				result = append(result, site)
+				break
+			}
 		}
 	}
-	return index
+	return result
 }
 
-func mustCreateFile(filename string) *os.File {
-	f, err := os.Create(filename)
-	if err != nil {
-		log.Fatalln(err)
+// writeTable outputs n rows in table format with fields as header.
+func writeTable(out io.Writer, fields []string, n int, value rowValue) error {
+	const padding = 3
+	w := tabwriter.NewWriter(out, 10, 0, padding, ' ', 0)
+	fmt.Fprintln(w, strings.Join(fields, "\t")+"\t")
+
+	record := make([]string, len(fields))
+	for row := 0; row < n; row++ {
+		for i, field := range fields {
+			record[i] = value(row, field)
+		}
+		fmt.Fprintln(w, strings.Join(record, "\t")+"\t")
 	}
-	return f
+	return w.Flush()
+}
+
+// writeCsv outputs n rows as csv with fields as header.
+func writeCsv(out io.Writer, fields []string, n int, value rowValue) error {
+	w := csv.NewWriter(out)
+	record := make([]string, len(fields))
+	copy(record, fields)
+	if err := w.Write(record); err != nil {
+		return err
+	}
+	for row := 0; row < n; row++ {
+		for i, field := range fields {
+			record[i] = value(row, field)
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// writeMarkdown outputs n rows as a GitHub flavoured
+// Markdown table with fields as header.
+func writeMarkdown(out io.Writer, fields []string, n int, value rowValue) error {
+	escape := strings.NewReplacer("|", "\\|", "\r\n", " ", "\n", " ")
+	record := make([]string, len(fields))
+	line := func() error {
+		_, err := fmt.Fprintf(out, "| %s |\n", strings.Join(record, " | "))
+		return err
+	}
+	for i, field := range fields {
+		record[i] = escape.Replace(field)
+	}
+	if err := line(); err != nil {
+		return err
+	}
+	for i := range record {
+		record[i] = "---"
+	}
+	if err := line(); err != nil {
+		return err
+	}
+	for row := 0; row < n; row++ {
+		for i, field := range fields {
+			record[i] = escape.Replace(value(row, field))
+		}
+		if err := line(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonRecord is one row as a JSON object with the fields
+// as keys in the order they were selected.
+type jsonRecord struct {
+	fields []string
+	row    int
+	cell   rowCell
+}
+
+func (r jsonRecord) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range r.fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(field)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(r.cell(r.row, field))
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// writeJSON outputs n rows as a JSON array of objects with the fields
+// as keys and typed values. Rows are written one at a time.
+func writeJSON(out io.Writer, fields []string, n int, cell rowCell) error {
+	if n == 0 {
+		_, err := io.WriteString(out, "[]")
+		return err
+	}
+	sep := "[\n    "
+	for row := 0; row < n; row++ {
+		data, err := json.MarshalIndent(jsonRecord{fields, row, cell}, "    ", "    ")
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(out, sep); err != nil {
+			return err
+		}
+		if _, err := out.Write(data); err != nil {
+			return err
+		}
+		sep = ",\n    "
+	}
+	_, err := io.WriteString(out, "\n]")
+	return err
+}
+
+// writeNDJSON outputs n rows as newline delimited JSON,
+// one object like in writeJSON per line.
+func writeNDJSON(out io.Writer, fields []string, n int, cell rowCell) error {
+	return encodeLines(out, n, func(row int) any {
+		return jsonRecord{fields, row, cell}
+	})
+}
+
+// encodeLines outputs n items as newline delimited JSON.
+func encodeLines(out io.Writer, n int, item func(i int) any) error {
+	enc := json.NewEncoder(out)
+	for i := 0; i < n; i++ {
+		if err := enc.Encode(item(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizeMac returns mac in the lower case colon separated
+// form used by the controller. Dashes, dots and missing
+// separators are accepted.
+func normalizeMac(mac string) string {
+	mac = strings.ToLower(strings.TrimSpace(mac))
+	mac = strings.NewReplacer("-", "", ":", "", ".", "").Replace(mac)
+	if len(mac) != 12 {
+		return mac
+	}
+	parts := make([]string, 6)
+	for i := range parts {
+		parts[i] = mac[i*2 : i*2+2]
+	}
+	return strings.Join(parts, ":")
 }
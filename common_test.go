@@ -4,37 +4,60 @@
 package main
 
 import (
+	"bytes"
+	"fmt"
+	"io"
 	"testing"
 )
 
-func Test_getColumns(t *testing.T) {
-	type args struct {
-		name []string
+func Test_writeJSON(t *testing.T) {
+	cell := func(row int, field string) any {
+		switch field {
+		case "Name":
+			return fmt.Sprintf("n%d", row)
+		case "Count":
+			return row
+		}
+		return nil
 	}
-	type pair struct {
-		name   string
-		result string
-	}
-
-	samples1 := []string{"MAC", "IP", "Hostname", "Name", "Network", "Switch", "Port", "AP"}
-
+	fields := []string{"Name", "Count", "Missing"}
 	tests := []struct {
-		name string
-		args args
-		want []pair
+		name   string
+		n      int
+		writer func(io.Writer, []string, int, rowCell) error
+		want   string
 	}{
-		{"0=A", args{samples1}, []pair{{"MAC", "A1"}}},
-		// TODO: Add test cases.
+		{"json", 2, writeJSON, "[\n    {\n        \"Name\": \"n0\",\n        \"Count\": 0,\n        \"Missing\": null\n    },\n    {\n        \"Name\": \"n1\",\n        \"Count\": 1,\n        \"Missing\": null\n    }\n]"},
+		{"empty json", 0, writeJSON, "[]"},
+		{"ndjson", 2, writeNDJSON, "{\"Name\":\"n0\",\"Count\":0,\"Missing\":null}\n{\"Name\":\"n1\",\"Count\":1,\"Missing\":null}\n"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := getColumns(tt.args.name...)
-			for _, v := range tt.want {
-				got2 := got[v.name](1)
-				if got2 != v.result {
-					t.Errorf("got[%s](1) = %s, want %s", v.name, got2, v.result)
-				}
+			var buf bytes.Buffer
+			if err := tt.writer(&buf, fields, tt.n, cell); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
 			}
 		})
 	}
 }
+
+func Test_writeMarkdown(t *testing.T) {
+	values := [][]string{{"sw-1", "a|b"}, {"sw-2", "line\nbreak"}}
+	var buf bytes.Buffer
+	err := writeMarkdown(&buf, []string{"Switch", "Name"}, len(values), func(row int, field string) string {
+		if field == "Switch" {
+			return values[row][0]
+		}
+		return values[row][1]
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "| Switch | Name |\n| --- | --- |\n| sw-1 | a\\|b |\n| sw-2 | line break |\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Config is the configuration file used by daemon.
+type Config struct {
+	// Output is the directory for timestamped results.
+	Output string `json:"output"`
+	// Latest is an optional directory that always has
+	// a copy of the latest result of each job.
+	Latest string `json:"latest"`
+	// RetentionDays is how long results are kept, 0 keeps them forever.
+	RetentionDays int         `json:"retention_days"`
+	Mail          *mailConfig `json:"mail"`
+	Jobs          []*Job      `json:"jobs"`
+}
+
+// Job is something that is run on a schedule.
+type Job struct {
+	Name     string   `json:"name"`
+	Schedule string   `json:"schedule"`
+	Type     string   `json:"type"`
+	Format   string   `json:"format"`
+	Sites    []string `json:"sites"`
+	Fields   []string `json:"fields"`
+
+	schedule *schedule
+}
+
+// loadConfig reads and validates a json configuration file.
+func loadConfig(filename string) (*Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	conf := &Config{Output: "out/results"}
+	if err := json.Unmarshal(data, conf); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", filename, err)
+	}
+	names := make(map[string]bool)
+	for _, job := range conf.Jobs {
+		if job.Name == "" {
+			job.Name = job.Type
+		}
+		if names[job.Name] {
+			return nil, fmt.Errorf("job '%s' is defined more than once", job.Name)
+		}
+		names[job.Name] = true
+		if job.schedule, err = parseSchedule(job.Schedule); err != nil {
+			return nil, fmt.Errorf("job '%s': %w", job.Name, err)
+		}
+		if err := job.validate(); err != nil {
+			return nil, fmt.Errorf("job '%s': %w", job.Name, err)
+		}
+	}
+	return conf, nil
+}
@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_scheduleNext(t *testing.T) {
+	// a wednesday
+	from := time.Date(2026, 1, 7, 10, 17, 30, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 1, 7, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 1, 7, 10, 30, 0, 0, time.UTC)},
+		{"0 6 * * *", time.Date(2026, 1, 8, 6, 0, 0, 0, time.UTC)},
+		{"30 6 * * 1-5", time.Date(2026, 1, 8, 6, 30, 0, 0, time.UTC)},
+		{"0 0 * * 0", time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC)},
+		{"0 12 1 * *", time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)},
+		{"0 12 1 * 5", time.Date(2026, 1, 9, 12, 0, 0, 0, time.UTC)},
+		{"0,45 10-11 * 3 *", time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		s, err := parseSchedule(tt.expr)
+		if err != nil {
+			t.Fatalf("parseSchedule(%q): %v", tt.expr, err)
+		}
+		if got := s.next(from); !got.Equal(tt.want) {
+			t.Errorf("next(%q) = %s, want %s", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func Test_parseScheduleErrors(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := parseSchedule(expr); err == nil {
+			t.Errorf("parseSchedule(%q) did not fail", expr)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/unpoller/unifi"
+)
+
+// resultTimeFormat prefixes every result written by daemon with the
+// local time as YYYY-MM-DD_HHMM so the files sort by when they ran.
+const resultTimeFormat = "2006-01-02_1504"
+
+var (
+	daemonCmd        = flag.NewFlagSet("daemon", flag.ExitOnError)
+	daemonConfigFlag = daemonCmd.String("config", "unimac.json", "configuration file with the jobs to run")
+	daemonRunFlag    = daemonCmd.String("run", "", "run the named job once and exit")
+
+	// jobFormats are the formats each type of job can write,
+	// the first one is the default.
+	jobFormats = map[string][]string{
+		"clients":  {"xlsx", "csv", "json", "ndjson", "md", "html"},
+		"devices":  {"xlsx", "csv", "json", "ndjson", "md", "html"},
+		"ports":    {"xlsx", "csv", "json", "ndjson", "md", "html"},
+		"snapshot": {"json"},
+		"audit":    {"html"},
+	}
+)
+
+func (job *Job) validate() error {
+	formats, ok := jobFormats[job.Type]
+	if !ok {
+		return fmt.Errorf("unknown type '%s'", job.Type)
+	}
+	if job.Format == "" {
+		job.Format = formats[0]
+	}
+	for _, f := range formats {
+		if f == job.Format {
+			return nil
+		}
+	}
+	return fmt.Errorf("type %s can not be written as %s", job.Type, job.Format)
+}
+
+func daemonRun(uni *unifi.Unifi, sites []*unifi.Site, args []string) {
+	check(daemonCmd.Parse(args))
+	conf, err := loadConfig(*daemonConfigFlag)
+	check(err)
+
+	if *daemonRunFlag != "" {
+		for _, job := range conf.Jobs {
+			if job.Name == *daemonRunFlag {
+				check(runJob(uni, sites, conf, job, time.Now()))
+				return
+			}
+		}
+		log.Fatalf("no job named '%s' in %s", *daemonRunFlag, *daemonConfigFlag)
+	}
+	if len(conf.Jobs) == 0 {
+		log.Fatalf("no jobs in %s", *daemonConfigFlag)
+	}
+
+	for {
+		next, due := nextJobs(conf.Jobs, time.Now())
+		if next.IsZero() {
+			log.Fatalln("no job will ever run again")
+		}
+		log.Printf("next run %s: %s", next.Format("2006-01-02 15:04"), jobNames(due))
+		time.Sleep(time.Until(next))
+
+		for _, job := range due {
+			if err := runJob(uni, sites, conf, job, next); err != nil {
+				log.Printf("Error running %s: %v", job.Name, err)
+			}
+		}
+		removed, err := prune(conf.Output, conf.RetentionDays, time.Now())
+		if err != nil {
+			log.Println("Error pruning:", err)
+		}
+		for _, name := range removed {
+			log.Println("removed", name)
+		}
+	}
+}
+
+// nextJobs returns the next time any job should run
+// and all the jobs that are due at that time.
+func nextJobs(jobs []*Job, now time.Time) (time.Time, []*Job) {
+	var next time.Time
+	var due []*Job
+	for _, job := range jobs {
+		t := job.schedule.next(now)
+		switch {
+		case t.IsZero():
+		case next.IsZero() || t.Before(next):
+			next = t
+			due = []*Job{job}
+		case t.Equal(next):
+			due = append(due, job)
+		}
+	}
+	return next, due
+}
+
+func jobNames(jobs []*Job) string {
+	names := make([]string, len(jobs))
+	for i, job := range jobs {
+		names[i] = job.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// runJob runs job and writes the result with a timestamp to the
+// output directory and as the latest copy if configured.
+func runJob(uni *unifi.Unifi, sites []*unifi.Site, conf *Config, job *Job, now time.Time) error {
+	sites = filterSites(sites, job.Sites)
+	ext := "." + job.Format
+	var buf bytes.Buffer
+
+	switch job.Type {
+	case "clients":
+		fields, err := parseFields(strings.Join(job.Fields, ","), client_fields)
+		if err != nil {
+			return err
+		}
+		clients, err := uni.GetClients(sites)
+		if err != nil {
+			return err
+		}
+		devices, err := uni.GetDevices(sites)
+		if err != nil {
+			return err
+		}
+		hydrateClients(clients, devices)
+		if err := getClientRender(ext)(&buf, clients, fields); err != nil {
+			return err
+		}
+	case "devices":
+		fields, err := parseFields(strings.Join(job.Fields, ","), device_all_fields)
+		if err != nil {
+			return err
+		}
+		devices, err := uni.GetDevices(sites)
+		if err != nil {
+			return err
+		}
+		if err := getDeviceRender(ext)(&buf, buildDevices(devices), fields); err != nil {
+			return err
+		}
+	case "ports":
+		fields, err := parseFields(strings.Join(job.Fields, ","), port_fields)
+		if err != nil {
+			return err
+		}
+		snap, err := takeSnapshot(uni, sites)
+		if err != nil {
+			return err
+		}
+		if err := getPortRender(ext)(&buf, snap.Ports, fields); err != nil {
+			return err
+		}
+	case "snapshot":
+		snap, err := takeSnapshot(uni, sites)
+		if err != nil {
+			return err
+		}
+		if err := encodeJSON(&buf, snap); err != nil {
+			return err
+		}
+	case "audit":
+		if err := runAudit(&buf, uni, sites, conf, job, now); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(conf.Output, 0755); err != nil {
+		return err
+	}
+	filename := filepath.Join(conf.Output, now.Format(resultTimeFormat)+"_"+job.Name+ext)
+	if err := os.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	log.Println("wrote", filename)
+
+	if conf.Latest != "" {
+		if err := os.MkdirAll(conf.Latest, 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(conf.Latest, job.Name+ext), buf.Bytes(), 0644)
+	}
+	return nil
+}
+
+// runAudit writes a summary of the changes since the previous
+// audit and mails it if mail is configured.
+func runAudit(buf *bytes.Buffer, uni *unifi.Unifi, sites []*unifi.Site, conf *Config, job *Job, now time.Time) error {
+	snap, err := takeSnapshot(uni, sites)
+	if err != nil {
+		return err
+	}
+	statefile := filepath.Join(conf.Output, job.Name+"-state.json")
+	prev, err := readReportState(statefile)
+	if err != nil {
+		return err
+	}
+	summary := summarize(prev, snap.Clients, snap.Devices, now)
+	if err := reportTemplate.Execute(buf, summary); err != nil {
+		return err
+	}
+	if conf.Mail != nil {
+		msg, err := buildMessage(conf.Mail.From, conf.Mail.To, "unimac "+job.Name, buf.String(), nil)
+		if err != nil {
+			return err
+		}
+		if err := sendMail(conf.Mail, msg); err != nil {
+			return err
+		}
+	}
+	return writeReportState(statefile, snap.Clients, snap.Devices, now)
+}
+
+// prune removes results in dir that are older than days.
+// Only files named with the result timestamp are considered.
+func prune(dir string, days int, now time.Time) ([]string, error) {
+	if days <= 0 {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	limit := now.AddDate(0, 0, -days)
+	var removed []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || len(name) < len(resultTimeFormat) {
+			continue
+		}
+		t, err := time.ParseInLocation(resultTimeFormat, name[:len(resultTimeFormat)], now.Location())
+		if err != nil || !t.Before(limit) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			return removed, err
+		}
+		removed = append(removed, name)
+	}
+	return removed, nil
+}
@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func Test_prune(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{
+		"2026-01-01_0600_clients.xlsx",
+		"2026-02-20_0600_clients.xlsx",
+		"2026-03-01_0600_devices.xlsx",
+		"audit-state.json",
+		"notes.txt",
+	}
+	for _, name := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.Local)
+	removed, err := prune(dir, 30, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"2026-01-01_0600_clients.xlsx"}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("removed %v, want %v", removed, want)
+	}
+	entries, _ := os.ReadDir(dir)
+	var left []string
+	for _, e := range entries {
+		left = append(left, e.Name())
+	}
+	sort.Strings(left)
+	if want := files[1:]; !reflect.DeepEqual(left, want) {
+		t.Errorf("left %v, want %v", left, want)
+	}
+}
+
+func Test_loadConfig(t *testing.T) {
+	conf, err := loadConfig("unimac.example.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conf.Jobs) != 4 || conf.Jobs[2].Format != "json" {
+		t.Errorf("unexpected jobs %+v", conf.Jobs)
+	}
+	next, due := nextJobs(conf.Jobs, time.Date(2026, 1, 7, 5, 50, 0, 0, time.Local))
+	if next.Hour() != 6 || next.Minute() != 0 || len(due) != 3 {
+		t.Errorf("next = %s %s", next, jobNames(due))
+	}
+}
@@ -5,23 +5,57 @@
 package main
 
 import (
-	"encoding/csv"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
-	"text/tabwriter"
 
 	"github.com/unpoller/unifi"
 	"github.com/xuri/excelize/v2"
 )
 
+const (
+	DEVICE_MAC      = "MAC"
+	DEVICE_TYPE     = "Type"
+	DEVICE_SITE     = "Site"
+	DEVICE_IP       = "IP"
+	DEVICE_NAME     = "Name"
+	DEVICE_NETWORK  = "Network"
+	DEVICE_UPLINK   = "Uplink"
+	DEVICE_UPPORT   = "UpPort"
+	DEVICE_CONFIGIP = "ConfigIP"
+	DEVICE_NOTE     = "Note"
+	DEVICE_STATE    = "State"
+)
+
 var (
-	devicesCmd       = flag.NewFlagSet("devices", flag.ExitOnError)
-	deviceOutputFlag = devicesCmd.String("output", "", "filename to output to. [*.xlsx, *.json, *.csv]")
+	devicesCmd         = flag.NewFlagSet("devices", flag.ExitOnError)
+	deviceOutputFlag   = devicesCmd.String("output", "", "filename to output to. [*.xlsx, *.json, *.ndjson, *.yaml, *.toml, *.csv, *.md, *.html]")
+	deviceSiteFlag     = devicesCmd.String("site", "", "comma separated list of sites to include")
+	deviceFieldsFlag   = devicesCmd.String("fields", "", "comma separated list of fields to output")
+	deviceMergeFlag    = devicesCmd.Bool("merge", false, "update an existing xlsx output by MAC and keep other columns")
+	deviceTemplateFlag = devicesCmd.String("template", "", "xlsx workbook to fill or text/template file to render instead of the output format")
+	deviceAnchorFlag   = devicesCmd.String("anchor", "", "defined name or cell in the template where the header is written")
+	deviceRawFlag      = devicesCmd.Bool("raw", false, "output json with every field of the devices")
+	deviceKeyFlag      = devicesCmd.String("key", "", "field to key yaml or toml output by, like MAC")
+	deviceAnsibleFlag  = devicesCmd.Bool("ansible", false, "output an ansible inventory, ini or yaml")
+	deviceMonitorFlag  = devicesCmd.String("monitor", "", "output host objects for icinga or nagios instead of the output format")
+	device_fields      = []string{
+		DEVICE_MAC, DEVICE_TYPE, DEVICE_SITE, DEVICE_IP, DEVICE_NAME,
+		DEVICE_NETWORK, DEVICE_UPLINK, DEVICE_UPPORT, DEVICE_CONFIGIP, DEVICE_NOTE,
+	}
+	// device_all_fields also has the fields that are
+	// only written when asked for with -fields.
+	device_all_fields = append(append([]string{}, device_fields...), DEVICE_STATE)
+	deviceStyle       = sheetStyle{offline: map[string]string{DEVICE_STATE: `"offline"`}}
 )
 
+// deviceRender outputs devices using the selected fields.
+// A nil list of fields means the default ones.
+type deviceRender func(io.Writer, []*Device, []string) error
+
 type DevicePort struct {
 	Mac  string
 	Name string
@@ -55,91 +89,215 @@ type Device struct {
 	Uplink        *DevicePort
 	Note          string
 	ConfigNetwork *unifi.ConfigNetwork
+	State         string
+	Model         string
+	Version       string
+}
+
+func (d *Device) Displayname() string {
+	if d.Name == "" {
+		return d.Mac
+	}
+	return d.Name
 }
 
 func generateDevices(uni *unifi.Unifi, sites []*unifi.Site) {
+	fields, err := parseFields(*deviceFieldsFlag, device_all_fields)
+	check(err)
+
+	sites = filterSites(sites, splitList(*deviceSiteFlag))
 	unifidevices, err := uni.GetDevices(sites)
 	if err != nil {
 		log.Fatalln("Error:", err)
 	}
+	// status goes to stderr as the output can be written to stdout
+	fmt.Fprintf(os.Stderr, "\t with %d USGs, %d USWs, %d UAPs and %d UXGs\n",
+		len(unifidevices.USGs), len(unifidevices.USWs), len(unifidevices.UAPs), len(unifidevices.UXGs))
 
-	// clients, err := uni.GetClients(sites)
-	// if err != nil {
-	// 	log.Fatalln("Error getting clients:", err)
-	// }
+	devices := buildDevices(unifidevices)
+	fmt.Fprintln(os.Stderr, len(devices), "Devices added")
 
-	switchmap := make(map[string]*unifi.USW)
+	ext := ".table"
+	if *deviceOutputFlag != "" {
+		ext = filepath.Ext(*deviceOutputFlag)
+	}
+	if *deviceMergeFlag && ext != ".xlsx" {
+		log.Fatalln("Error: -merge needs an xlsx output")
+	}
+	if *deviceMergeFlag && canMerge(*deviceOutputFlag) {
+		if fields == nil {
+			fields = device_fields
+		}
+		result, err := mergeFile(*deviceOutputFlag, fields, len(devices), deviceValues(devices), deviceCells(devices))
+		if err != nil {
+			log.Fatalln("Error:", err)
+		}
+		fmt.Printf("merged into %s, %s\n", *deviceOutputFlag, result)
+		return
+	}
+	renderer := getDeviceRender(ext)
+	switch {
+	case filepath.Ext(*deviceTemplateFlag) == ".xlsx":
+		if ext != ".xlsx" {
+			log.Fatalln("Error: an xlsx template needs an xlsx output")
+		}
+		renderer = func(out io.Writer, devices []*Device, fields []string) error {
+			if fields == nil {
+				fields = device_fields
+			}
+			return writeTemplate(out, *deviceTemplateFlag, *deviceAnchorFlag, fields, len(devices), deviceCells(devices), deviceStyle)
+		}
+	case *deviceTemplateFlag != "":
+		renderer = func(out io.Writer, devices []*Device, fields []string) error {
+			if fields == nil {
+				fields = device_fields
+			}
+			return writeTextTemplate(out, *deviceTemplateFlag, fields, len(devices), deviceValues(devices))
+		}
+	}
+	if *deviceAnsibleFlag {
+		if renderer = deviceAnsible(ext); renderer == nil {
+			log.Fatalln("Error: -ansible needs ini or yaml output")
+		}
+	}
+	if *deviceMonitorFlag != "" {
+		if renderer = deviceMonitor(*deviceMonitorFlag); renderer == nil {
+			log.Fatalln("Error: -monitor takes icinga or nagios")
+		}
+	}
+	if renderer == nil {
+		log.Fatalf("unsupported extension for %s", *deviceOutputFlag)
+	}
+	if *deviceRawFlag {
+		if renderer = deviceRaw(ext); renderer == nil {
+			log.Fatalln("Error: -raw needs json or ndjson output")
+		}
+	}
+	if *deviceKeyFlag != "" {
+		key, err := parseFields(*deviceKeyFlag, device_all_fields)
+		check(err)
+		if len(key) != 1 {
+			log.Fatalln("Error: -key takes a single field")
+		}
+		if renderer = deviceKeyed(ext, key[0]); renderer == nil {
+			log.Fatalln("Error: -key needs yaml or toml output")
+		}
+	}
+	if *deviceOutputFlag != "" {
+		f := mustCreateFile(*deviceOutputFlag)
+		defer f.Close()
+		err = renderer(f, devices, fields)
+	} else {
+		err = renderer(os.Stdout, devices, fields)
+	}
+	if err != nil {
+		log.Fatalf("error writing '%s': %v", *deviceOutputFlag, err)
+	}
+}
+
+// getDeviceRender returns the renderer for a file extension
+// or nil if the extension is not supported.
+func getDeviceRender(ext string) deviceRender {
+	switch ext {
+	case ".xlsx":
+		return devicesExcel
+	case ".json":
+		return devicesJSON
+	case ".ndjson":
+		return devicesNDJSON
+	case ".yaml", ".yml":
+		return devicesYAML
+	case ".toml":
+		return devicesTOML
+	case ".csv":
+		return devicesCsv
+	case ".md":
+		return devicesMarkdown
+	case ".html":
+		return devicesHTML
+	case ".table":
+		return deviceTable
+	}
+	return nil
+}
+
+// buildDevices flattens the devices reported by the controller
+// into a list of Device with uplink information.
+func buildDevices(unifidevices *unifi.Devices) []*Device {
 	dlmap := make(map[string]*DevicePort)
 	for _, sw := range unifidevices.USWs {
-		switchmap[sw.Mac] = sw
 		// fmt.Println("sw: ", sw.Mac)
 		for _, dl := range sw.DownlinkTable {
 			// fmt.Printf("\t %s, %s\n", dl.Mac, dl.PortIdx.String())
 			dlmap[dl.Mac] = &DevicePort{Mac: sw.Mac, Name: sw.Name, Port: dl.PortIdx.String()}
 		}
 	}
-
-	apmap := make(map[string]*unifi.UAP)
-	for _, ap := range unifidevices.UAPs {
-		apmap[ap.Mac] = ap
+	for _, dm := range unifidevices.UDMs {
+		for _, dl := range dm.DownlinkTable {
+			dlmap[dl.Mac] = &DevicePort{Mac: dm.Mac, Name: dm.Name, Port: dl.PortIdx.String()}
+		}
 	}
 
 	// gwmap := make(map[string]*unifi.USG)
 	// for _, sg := range devices.USGs {
 	// 	gwmap[sg.Mac] = sg
 	// }
-	// clientmap := make(map[string]*unifi.Client)
-	// for _, cl := range clients {
-	// 	clientmap[cl.Mac] = cl
-	// }
 	var devices []*Device
-	before := len(devices)
-	fmt.Printf("\t with %d USGs", len(unifidevices.USGs))
 	withUSGs(unifidevices, &devices)
-	fmt.Printf(" added %d \n", len(devices)-before)
-
-	before = len(devices)
-	fmt.Printf("\t with %d USWs", len(unifidevices.USWs))
 	withUSWs(unifidevices, &devices, dlmap)
-	fmt.Printf(" added %d\n", len(devices)-before)
-
-	fmt.Printf("\t with %d UAPs", len(unifidevices.UAPs))
-	before = len(devices)
 	withUAPs(unifidevices, &devices, dlmap)
-	fmt.Printf(" added %d\n", len(devices)-before)
 
-	fmt.Printf("\t with %d UXGs\n", len(unifidevices.UXGs))
 	for _, xg := range unifidevices.UXGs {
 		ul := dlmap[xg.Mac]
 		d := &Device{
-			Mac:    xg.Mac,
-			Site:   xg.SiteName,
-			Name:   xg.Name,
-			IP:     xg.IP,
-			Type:   "UXG",
-			Uplink: ul, //DevicePort{Mac: ap.Uplink.Mac, Port: strconv.Itoa(ap.Uplink.UplinkRemotePort)},
+			Mac:     xg.Mac,
+			Site:    xg.SiteName,
+			Name:    xg.Name,
+			IP:      xg.IP,
+			Type:    "UXG",
+			State:   deviceState(xg.State.Val),
+			Model:   xg.Model,
+			Version: xg.Version,
+			Uplink:  ul, //DevicePort{Mac: ap.Uplink.Mac, Port: strconv.Itoa(ap.Uplink.UplinkRemotePort)},
 		}
 		devices = append(devices, d)
 	}
-
-	if *deviceOutputFlag == "" {
-		deviceTable(devices)
-	} else {
-		ext := filepath.Ext(*deviceOutputFlag)
-		switch ext {
-		case ".json":
-			err := writeJSON(devices, *deviceOutputFlag)
-			if err != nil {
-				log.Fatalf("error writing '%s': %v", *deviceOutputFlag, err)
-			}
-		case ".csv":
-			devicesCsv(devices, *deviceOutputFlag)
-		case ".xlsx":
-			devicesExcel(devices, *deviceOutputFlag)
-		default:
-			log.Fatalf("unsupported extension for %s", *deviceOutputFlag)
+	for _, dm := range unifidevices.UDMs {
+		d := &Device{
+			Mac:           dm.Mac,
+			Site:          dm.SiteName,
+			Name:          dm.Name,
+			IP:            dm.IP,
+			Type:          "UDM",
+			Uplink:        dlmap[dm.Mac],
+			ConfigNetwork: dm.ConfigNetwork,
+			State:         deviceState(dm.State.Val),
+			Model:         dm.Model,
+			Version:       dm.Version,
 		}
+		devices = append(devices, d)
+	}
+	return devices
+}
+
+// deviceStates names the device state codes used by the controller.
+var deviceStates = map[int]string{
+	0:  "offline",
+	1:  "online",
+	2:  "pending adoption",
+	4:  "upgrading",
+	5:  "provisioning",
+	6:  "heartbeat missed",
+	7:  "adopting",
+	9:  "adoption failed",
+	10: "isolated",
+}
+
+func deviceState(state float64) string {
+	if name, ok := deviceStates[int(state)]; ok {
+		return name
 	}
+	return fmt.Sprintf("state %d", int(state))
 }
 
 func withUSGs(unifidevices *unifi.Devices, devices *[]*Device) {
@@ -158,6 +316,9 @@ func withUSGs(unifidevices *unifi.Devices, devices *[]*Device) {
 			Type:          "USG",
 			Uplink:        ul,
 			ConfigNetwork: sg.ConfigNetwork,
+			State:         deviceState(sg.State.Val),
+			Model:         sg.Model,
+			Version:       sg.Version,
 		}
 
 		*devices = append(*devices, d)
@@ -174,6 +335,9 @@ func withUSWs(unifidevices *unifi.Devices, devices *[]*Device, dlmap map[string]
 			IP:            sw.IP,
 			Type:          "USW",
 			ConfigNetwork: sw.ConfigNetwork,
+			State:         deviceState(sw.State.Val),
+			Model:         sw.Model,
+			Version:       sw.Version,
 		}
 
 		if val, ok := dlmap[sw.Mac]; ok {
@@ -191,11 +355,14 @@ func withUAPs(unifidevices *unifi.Devices, devices *[]*Device, dlmap map[string]
 	for _, ap := range unifidevices.UAPs {
 		ul := dlmap[ap.Mac]
 		d := &Device{
-			Mac:  ap.Mac,
-			Site: ap.SiteName,
-			Name: ap.Name,
-			IP:   ap.IP,
-			Type: "UAP",
+			Mac:     ap.Mac,
+			Site:    ap.SiteName,
+			Name:    ap.Name,
+			IP:      ap.IP,
+			Type:    "UAP",
+			State:   deviceState(ap.State.Val),
+			Model:   ap.Model,
+			Version: ap.Version,
 			// Uplink: *ul, //DevicePort{Mac: ap.Uplink.Mac, Port: strconv.Itoa(ap.Uplink.UplinkRemotePort)},
 			// ConfigNetwork: &unifi.ConfigNetwork{IP: ap.ConfigNetwork.IP, Type: ap.ConfigNetwork.Type},
 		}
@@ -209,114 +376,179 @@ func withUAPs(unifidevices *unifi.Devices, devices *[]*Device, dlmap map[string]
 	}
 }
 
-func deviceTable(devices []*Device) {
-
-	const padding = 3
-	w := tabwriter.NewWriter(os.Stdout, 10, 0, padding, ' ', 0)
-	fmt.Fprintln(w, "Mac\tType\tSite\tIP\tName\tNetwork\tUplink\tUpPort\tConfigIP\tNote\t")
-	template := "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n"
-	for _, d := range devices {
-		ul_name := "nil"
-		ul_port := "nil"
-		if d.Uplink != nil {
-			ul_name = d.Uplink.Displayname()
-			ul_port = d.Uplink.Port
+func getDeviceValue(d *Device, name string) string {
+	switch name {
+	case DEVICE_MAC:
+		return d.Mac
+	case DEVICE_TYPE:
+		return d.Type
+	case DEVICE_SITE:
+		return d.Site
+	case DEVICE_IP:
+		return d.IP
+	case DEVICE_NAME:
+		return d.Name
+	case DEVICE_NETWORK:
+		return ""
+	case DEVICE_UPLINK:
+		if d.Uplink == nil {
+			return "nil"
+		}
+		return d.Uplink.Displayname()
+	case DEVICE_UPPORT:
+		if d.Uplink == nil {
+			return "nil"
 		}
-		fmt.Fprintf(w, template,
-			d.Mac, d.Type, d.Site,
-			d.IP,
-			d.Name,
-			"",
-			ul_name,
-			ul_port,
-			d.ConfigNetwork.IP,
-			d.Note,
-		)
-	}
-	w.Flush()
+		return d.Uplink.Port
+	case DEVICE_CONFIGIP:
+		if d.ConfigNetwork == nil {
+			return "nil"
+		}
+		return d.ConfigNetwork.IP
+	case DEVICE_NOTE:
+		return d.Note
+	case DEVICE_STATE:
+		return d.State
+	default:
+		return "#UNSUPPORTED"
+	}
 }
 
-func devicesCsv(devices []*Device, filename string) {
-	f, err := os.Create(filename)
-	if err != nil {
-		log.Fatalln(err)
-	}
-	defer f.Close()
-	w := csv.NewWriter(f)
-	record := make([]string, 10)
-	record[0] = "MAC"
-	record[1] = "Type"
-	record[2] = "Site"
-	record[3] = "IP"
-	record[4] = "Name"
-	record[5] = "Network"
-	record[6] = "Uplink"
-	record[7] = "UpPort"
-	record[8] = "ConfigIP"
-	record[9] = "Note"
-	if err := w.Write(record); err != nil {
-		log.Fatalln(err)
-	}
-	for _, d := range devices {
-		ul_name := "nil"
-		ul_port := "nil"
-		if d.Uplink != nil {
-			ul_name = d.Uplink.Displayname()
-			ul_port = d.Uplink.Port
+// deviceValues returns a rowValue for a list of devices
+func deviceValues(devices []*Device) rowValue {
+	return func(row int, field string) string {
+		return getDeviceValue(devices[row], field)
+	}
+}
+
+// getDeviceCell returns the value of a device field typed for Excel
+// and JSON. Uplink and config IP are nil when the device has none.
+func getDeviceCell(d *Device, name string) any {
+	switch name {
+	case DEVICE_UPLINK, DEVICE_UPPORT:
+		if d.Uplink == nil {
+			return nil
 		}
-		cfn_ip := "nil"
-		if d.ConfigNetwork != nil {
-			cfn_ip = d.ConfigNetwork.IP
+	case DEVICE_CONFIGIP:
+		if d.ConfigNetwork == nil {
+			return nil
 		}
-		err := w.Write([]string{
-			d.Mac, d.Type, d.Site, d.IP, d.Name, "",
-			ul_name, ul_port,
-			cfn_ip,
-			d.Note,
-		})
-		check(err)
 	}
-	w.Flush()
+	return getDeviceValue(d, name)
 }
 
-func devicesExcel(devices []*Device, filename string) {
-	f := excelize.NewFile()
-	sname := f.GetSheetName(0)
-	check(f.SetCellValue(sname, "A1", "MAC"))
-	check(f.SetCellValue(sname, "B1", "Type"))
-	check(f.SetCellValue(sname, "C1", "Site"))
-	check(f.SetCellValue(sname, "D1", "IP"))
-	check(f.SetCellValue(sname, "E1", "Name"))
-	check(f.SetCellValue(sname, "F1", "Network"))
-	check(f.SetCellValue(sname, "G1", "Uplink"))
-	check(f.SetCellValue(sname, "H1", "UpPort"))
-	check(f.SetCellValue(sname, "I1", "ConfigIP"))
-	check(f.SetCellValue(sname, "J1", "Note"))
-	row := 2
-	for _, d := range devices {
-		ul_name := "nil"
-		ul_port := "nil"
-		if d.Uplink != nil {
-			ul_name = d.Uplink.Displayname()
-			ul_port = d.Uplink.Port
+// deviceCells returns a rowCell for a list of devices
+func deviceCells(devices []*Device) rowCell {
+	return func(row int, field string) any {
+		return getDeviceCell(devices[row], field)
+	}
+}
+
+func deviceTable(out io.Writer, devices []*Device, fields []string) error {
+	if fields == nil {
+		fields = device_fields
+	}
+	return writeTable(out, fields, len(devices), deviceValues(devices))
+}
+
+func devicesJSON(out io.Writer, devices []*Device, fields []string) error {
+	if fields == nil {
+		fields = device_fields
+	}
+	return writeJSON(out, fields, len(devices), deviceCells(devices))
+}
+
+func devicesNDJSON(out io.Writer, devices []*Device, fields []string) error {
+	if fields == nil {
+		fields = device_fields
+	}
+	return writeNDJSON(out, fields, len(devices), deviceCells(devices))
+}
+
+func devicesYAML(out io.Writer, devices []*Device, fields []string) error {
+	if fields == nil {
+		fields = device_fields
+	}
+	return writeYAML(out, fields, len(devices), deviceCells(devices), nil)
+}
+
+func devicesTOML(out io.Writer, devices []*Device, fields []string) error {
+	if fields == nil {
+		fields = device_fields
+	}
+	return writeTOML(out, "devices", fields, len(devices), deviceCells(devices), nil)
+}
+
+// deviceKeyed returns a renderer for the devices as a dictionary keyed
+// by the field key or nil if ext is not yaml or toml.
+func deviceKeyed(ext, key string) deviceRender {
+	if ext != ".yaml" && ext != ".yml" && ext != ".toml" {
+		return nil
+	}
+	return func(out io.Writer, devices []*Device, fields []string) error {
+		if fields == nil {
+			fields = device_fields
 		}
-		check(f.SetCellValue(sname, fmt.Sprintf("A%d", row), d.Mac))
-		check(f.SetCellValue(sname, fmt.Sprintf("B%d", row), d.Type))
-		check(f.SetCellValue(sname, fmt.Sprintf("C%d", row), d.Site))
-		check(f.SetCellValue(sname, fmt.Sprintf("D%d", row), d.IP))
-		check(f.SetCellValue(sname, fmt.Sprintf("E%d", row), d.Name))
-		check(f.SetCellValue(sname, fmt.Sprintf("F%d", row), ""))
-		check(f.SetCellValue(sname, fmt.Sprintf("G%d", row), ul_name))
-		check(f.SetCellValue(sname, fmt.Sprintf("H%d", row), ul_port))
-		if d.ConfigNetwork != nil {
-			check(f.SetCellValue(sname, fmt.Sprintf("I%d", row), d.ConfigNetwork.IP))
-		} else {
-			check(f.SetCellValue(sname, fmt.Sprintf("I%d", row), "nil"))
+		keys, err := dictKeys(len(devices), key, deviceValues(devices))
+		if err != nil {
+			return err
+		}
+		if ext == ".toml" {
+			return writeTOML(out, "devices", fields, len(devices), deviceCells(devices), keys)
 		}
-		check(f.SetCellValue(sname, fmt.Sprintf("J%d", row), d.Note))
-		row++
+		return writeYAML(out, fields, len(devices), deviceCells(devices), keys)
+	}
+}
+
+// deviceRaw returns a renderer for the devices with every field
+// or nil if ext is not json or ndjson.
+func deviceRaw(ext string) deviceRender {
+	switch ext {
+	case ".json":
+		return func(out io.Writer, devices []*Device, _ []string) error {
+			return encodeJSON(out, devices)
+		}
+	case ".ndjson":
+		return func(out io.Writer, devices []*Device, _ []string) error {
+			return encodeLines(out, len(devices), func(i int) any { return devices[i] })
+		}
+	}
+	return nil
+}
+
+func devicesCsv(out io.Writer, devices []*Device, fields []string) error {
+	if fields == nil {
+		fields = device_fields
+	}
+	return writeCsv(out, fields, len(devices), deviceValues(devices))
+}
+
+func devicesExcel(out io.Writer, devices []*Device, fields []string) error {
+	if fields == nil {
+		fields = device_fields
 	}
-	if err := f.SaveAs(*deviceOutputFlag); err != nil {
-		log.Println(err)
+	f := excelize.NewFile()
+	if err := writeSheet(f, f.GetSheetName(0), fields, len(devices), deviceCells(devices), deviceStyle); err != nil {
+		return err
+	}
+	return f.Write(out)
+}
+
+// devicesSheet writes devices to the sheet sname of f
+func devicesSheet(f *excelize.File, sname string, devices []*Device) error {
+	return writeSheet(f, sname, device_fields, len(devices), deviceCells(devices), deviceStyle)
+}
+
+func devicesMarkdown(out io.Writer, devices []*Device, fields []string) error {
+	if fields == nil {
+		fields = device_fields
+	}
+	return writeMarkdown(out, fields, len(devices), deviceValues(devices))
+}
+
+func devicesHTML(out io.Writer, devices []*Device, fields []string) error {
+	if fields == nil {
+		fields = device_fields
 	}
+	return writeHTML(out, "Devices", fields, len(devices), deviceValues(devices))
 }
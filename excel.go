@@ -0,0 +1,290 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	// weakRssi is the RSSI below which a client is marked red.
+	weakRssi = 20
+	// maxColWidth keeps long notes from making huge columns.
+	maxColWidth = 60
+)
+
+var dateFormat = "yyyy-mm-dd hh:mm:ss"
+
+// sheetStyle is the formatting of the fields of one kind of rows.
+// Fields are only known by their header and the same header can mean
+// different things for clients, devices and ports.
+type sheetStyle struct {
+	// dates are formatted as dates in Excel.
+	dates map[string]bool
+	// rssi is marked red below weakRssi.
+	rssi string
+	// offline marks the whole row grey when the
+	// field has the formula value given.
+	offline map[string]string
+}
+
+// rowCell returns the value of field for the item at index row
+// typed for Excel, numbers as numbers and dates as time.Time.
+type rowCell func(row int, field string) any
+
+// anchor is the cell where the header of a sheet is written,
+// the rows follow below it.
+type anchor struct {
+	sheet    string
+	col, row int
+}
+
+// topLeft is the anchor at A1 of sname.
+func topLeft(sname string) anchor {
+	return anchor{sheet: sname, col: 1, row: 1}
+}
+
+// cell returns the name of the cell col columns right and
+// row rows below the anchor.
+func (a anchor) cell(col, row int) string {
+	name, err := excelize.CoordinatesToCellName(a.col+col, a.row+row)
+	check(err)
+	return name
+}
+
+// column returns the name of the column col columns right of the anchor.
+func (a anchor) column(col int) string {
+	name, err := excelize.ColumnNumberToName(a.col + col)
+	check(err)
+	return name
+}
+
+// tableName creates a valid table name from a sheet name.
+func tableName(sname string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, sname)
+	return "Table_" + name
+}
+
+// writeSheet writes n rows to the empty sheet sname of f with fields as
+// header and formats it as a table. The rows are streamed so that large
+// sites don't need every cell in memory, use writeAt for a sheet that
+// already has content.
+func writeSheet(f *excelize.File, sname string, fields []string, n int, cell rowCell, style sheetStyle) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	at := topLeft(sname)
+	sw, err := f.NewStreamWriter(sname)
+	if err != nil {
+		return err
+	}
+	for i, width := range columnWidths(fields, n, cell, style) {
+		if err := sw.SetColWidth(i+1, i+1, width); err != nil {
+			return err
+		}
+	}
+	if err := sw.SetPanes(headerPanes(at)); err != nil {
+		return err
+	}
+	dateStyle, err := newDateStyle(f)
+	if err != nil {
+		return err
+	}
+
+	values := make([]any, len(fields))
+	for i, field := range fields {
+		values[i] = field
+	}
+	if err := sw.SetRow(at.cell(0, 0), values); err != nil {
+		return err
+	}
+	for row := 0; row < n; row++ {
+		for i, field := range fields {
+			values[i] = cell(row, field)
+			if style.dates[field] {
+				values[i] = excelize.Cell{StyleID: dateStyle, Value: values[i]}
+			}
+		}
+		if err := sw.SetRow(at.cell(0, row+1), values); err != nil {
+			return err
+		}
+	}
+
+	ref := at.cell(0, 0) + ":" + at.cell(len(fields)-1, n)
+	if n > 0 {
+		err = sw.AddTable(&excelize.Table{
+			Range:     ref,
+			Name:      tableName(sname),
+			StyleName: "TableStyleMedium2",
+		})
+		if err == nil {
+			err = conditionalFormats(f, at, fields, n, style)
+		}
+	} else {
+		err = f.AutoFilter(sname, ref, nil)
+	}
+	if err != nil {
+		return err
+	}
+	return sw.Flush()
+}
+
+// writeAt writes fields as header at the anchor at and n rows below it
+// and formats it as a table. Other cells in the sheet are kept.
+func writeAt(f *excelize.File, at anchor, fields []string, n int, cell rowCell, style sheetStyle) error {
+	for i, field := range fields {
+		if err := f.SetCellValue(at.sheet, at.cell(i, 0), field); err != nil {
+			return err
+		}
+	}
+	for row := 0; row < n; row++ {
+		for i, field := range fields {
+			if err := f.SetCellValue(at.sheet, at.cell(i, row+1), cell(row, field)); err != nil {
+				return err
+			}
+		}
+	}
+	for i, width := range columnWidths(fields, n, cell, style) {
+		col := at.column(i)
+		if err := f.SetColWidth(at.sheet, col, col, width); err != nil {
+			return err
+		}
+	}
+	return formatSheet(f, at, fields, n, style)
+}
+
+// formatSheet turns n rows written below a header of fields at the anchor
+// into an Excel table with a frozen header, date formats and conditional
+// formatting of weak and offline rows.
+func formatSheet(f *excelize.File, at anchor, fields []string, n int, style sheetStyle) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	ref := at.cell(0, 0) + ":" + at.cell(len(fields)-1, n)
+	var err error
+	if n > 0 {
+		err = f.AddTable(at.sheet, &excelize.Table{
+			Range:     ref,
+			Name:      tableName(at.sheet),
+			StyleName: "TableStyleMedium2",
+		})
+	} else {
+		err = f.AutoFilter(at.sheet, ref, nil)
+	}
+	if err != nil {
+		return err
+	}
+	if err := f.SetPanes(at.sheet, headerPanes(at)); err != nil {
+		return err
+	}
+
+	dateStyle, err := newDateStyle(f)
+	if err != nil {
+		return err
+	}
+	for i, field := range fields {
+		if style.dates[field] && n > 0 {
+			if err := f.SetCellStyle(at.sheet, at.cell(i, 1), at.cell(i, n), dateStyle); err != nil {
+				return err
+			}
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+	return conditionalFormats(f, at, fields, n, style)
+}
+
+// headerPanes freezes the rows down to the header at the anchor.
+func headerPanes(at anchor) *excelize.Panes {
+	return &excelize.Panes{
+		Freeze:      true,
+		YSplit:      at.row,
+		TopLeftCell: fmt.Sprintf("A%d", at.row+1),
+		ActivePane:  "bottomLeft",
+	}
+}
+
+func newDateStyle(f *excelize.File) (int, error) {
+	return f.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat})
+}
+
+// columnWidths returns widths for the fields that fit
+// the header and the longest value of n rows.
+func columnWidths(fields []string, n int, cell rowCell, style sheetStyle) []float64 {
+	widths := make([]float64, len(fields))
+	for i, field := range fields {
+		width := utf8.RuneCountInString(field)
+		for row := 0; row < n; row++ {
+			l := len(dateFormat)
+			if !style.dates[field] {
+				l = 0
+				if v := cell(row, field); v != nil {
+					l = utf8.RuneCountInString(fmt.Sprint(v))
+				}
+			}
+			if l > width {
+				width = l
+			}
+		}
+		// room for the filter button
+		width += 4
+		if width > maxColWidth {
+			width = maxColWidth
+		}
+		widths[i] = float64(width)
+	}
+	return widths
+}
+
+// conditionalFormats marks weak RSSI red and offline rows grey.
+func conditionalFormats(f *excelize.File, at anchor, fields []string, n int, style sheetStyle) error {
+	red, err := f.NewConditionalStyle(&excelize.Style{
+		Font: &excelize.Font{Color: "9C0006"},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"FFC7CE"}},
+	})
+	if err != nil {
+		return err
+	}
+	grey, err := f.NewConditionalStyle(&excelize.Style{
+		Font: &excelize.Font{Color: "808080"},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"EDEDED"}},
+	})
+	if err != nil {
+		return err
+	}
+	rows := at.cell(0, 1) + ":" + at.cell(len(fields)-1, n)
+	for i, field := range fields {
+		first := at.cell(i, 1)
+		if field == style.rssi {
+			err = f.SetConditionalFormat(at.sheet, first+":"+at.cell(i, n), []excelize.ConditionalFormatOptions{{
+				Type:     "formula",
+				Criteria: fmt.Sprintf("AND(ISNUMBER(%s),%s<%d)", first, first, weakRssi),
+				Format:   red,
+			}})
+		}
+		if value, ok := style.offline[field]; ok {
+			err = f.SetConditionalFormat(at.sheet, rows, []excelize.ConditionalFormatOptions{{
+				Type:     "formula",
+				Criteria: fmt.Sprintf("$%s=%s", first, value),
+				Format:   grey,
+			}})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/unpoller/unifi"
+	"github.com/xuri/excelize/v2"
+)
+
+func Test_clientExcelTyped(t *testing.T) {
+	seen := time.Date(2026, 1, 2, 3, 4, 5, 0, time.Local)
+	clients := []*unifi.Client{
+		{Mac: "00:11:22:33:44:55", SwMac: "sw", SwName: "sw-1", SwPort: unifi.FlexInt{Val: 7, Txt: "7"}, LastSeen: unifi.FlexInt{Val: float64(seen.Unix())}},
+		{Mac: "66:77:88:99:aa:bb", ApMac: "ap", ApName: "ap-1", Rssi: unifi.FlexInt{Val: 12, Txt: "12"}},
+	}
+	var buf bytes.Buffer
+	if err := clientExcel(&buf, clients, nil); err != nil {
+		t.Fatal(err)
+	}
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sname := f.GetSheetName(0)
+	tests := []struct {
+		cell string
+		want string
+		raw  bool
+	}{
+		{"H2", "7", true},
+		{"J2", "", true},
+		{"J3", "12", true},
+		{"K2", "2026-01-02 03:04:05", false},
+	}
+	for _, tt := range tests {
+		got, err := f.GetCellValue(sname, tt.cell, excelize.Options{RawCellValue: tt.raw})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.cell, got, tt.want)
+		}
+	}
+	if typ, _ := f.GetCellType(sname, "H2"); typ != excelize.CellTypeNumber && typ != excelize.CellTypeUnset {
+		t.Errorf("H2 has type %v, want a number", typ)
+	}
+}
+
+func benchmarkClients(n int) []*unifi.Client {
+	clients := make([]*unifi.Client, n)
+	for i := range clients {
+		clients[i] = &unifi.Client{
+			Mac:      fmt.Sprintf("00:11:22:%02x:%02x:%02x", i>>16&0xff, i>>8&0xff, i&0xff),
+			IP:       fmt.Sprintf("10.%d.%d.%d", i>>16&0xff, i>>8&0xff, i&0xff),
+			Hostname: fmt.Sprintf("host-%d", i),
+			SiteName: "default",
+			ApMac:    "ap",
+			ApName:   "ap-1",
+			Rssi:     unifi.FlexInt{Val: float64(i % 60)},
+			LastSeen: unifi.FlexInt{Val: float64(1767225600 + i)},
+		}
+	}
+	return clients
+}
+
+// Benchmark_writeSheet streams the rows, compare with Benchmark_writeAt.
+func Benchmark_writeSheet(b *testing.B) {
+	clients := benchmarkClients(20000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		f := excelize.NewFile()
+		if err := writeSheet(f, "Sheet1", client_fields, len(clients), clientCells(clients), clientStyle); err != nil {
+			b.Fatal(err)
+		}
+		if err := f.Write(io.Discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Benchmark_writeAt sets every cell in memory like writing before streaming.
+func Benchmark_writeAt(b *testing.B) {
+	clients := benchmarkClients(20000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		f := excelize.NewFile()
+		if err := writeAt(f, topLeft("Sheet1"), client_fields, len(clients), clientCells(clients), clientStyle); err != nil {
+			b.Fatal(err)
+		}
+		if err := f.Write(io.Discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Test_writeSheetStyle(t *testing.T) {
+	tests := []struct {
+		name  string
+		style sheetStyle
+		want  int
+	}{
+		{"devices", deviceStyle, 1},
+		{"sites", sheetStyle{}, 0},
+		{"ports", portStyle, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			f := excelize.NewFile()
+			sname := f.GetSheetName(0)
+			err := writeSheet(f, sname, []string{DEVICE_NAME, DEVICE_STATE}, 1, func(row int, field string) any {
+				return "offline"
+			}, tt.style)
+			if err == nil {
+				err = f.Write(&buf)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			f, err = excelize.OpenReader(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			formats, err := f.GetConditionalFormats(sname)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(formats) != tt.want {
+				t.Errorf("conditional formats = %v, want %d", formats, tt.want)
+			}
+		})
+	}
+}
+
+func Test_writeSheetEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := clientExcel(&buf, nil, []string{CLIENT_MAC, CLIENT_IP}); err != nil {
+		t.Fatal(err)
+	}
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := f.GetRows(f.GetSheetName(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || len(rows[0]) != 2 || rows[0][1] != CLIENT_IP {
+		t.Errorf("rows = %v, want only the header", rows)
+	}
+}
+
+func Test_writeSheetColumns(t *testing.T) {
+	fields := make([]string, 30)
+	for i := range fields {
+		fields[i] = fmt.Sprintf("f%d", i)
+	}
+	f := excelize.NewFile()
+	sname := f.GetSheetName(0)
+	err := writeSheet(f, sname, fields, 1, func(row int, field string) any {
+		return field + "-value"
+	}, sheetStyle{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		cell string
+		want string
+	}{
+		{"A1", "f0"},
+		{"Z1", "f25"},
+		{"AA1", "f26"},
+		{"AB1", "f27"},
+		{"AD1", "f29"},
+		{"AA2", "f26-value"},
+		{"AD2", "f29-value"},
+		{"AE1", ""},
+	}
+	for _, tt := range tests {
+		got, err := f.GetCellValue(sname, tt.cell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.cell, got, tt.want)
+		}
+	}
+}
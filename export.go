@@ -0,0 +1,143 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/unpoller/unifi"
+	"github.com/xuri/excelize/v2"
+)
+
+var (
+	exportCmd         = flag.NewFlagSet("export", flag.ExitOnError)
+	exportOutputFlag  = exportCmd.String("output", "inventory.xlsx", "workbook to write, or a .sqlite database to add the export to")
+	exportSiteFlag    = exportCmd.String("site", "", "comma separated list of sites to include")
+	exportPerSiteFlag = exportCmd.Bool("per-site", false, "add a sheet with the clients of each site")
+)
+
+func exportRun(uni *unifi.Unifi, sites []*unifi.Site, args []string) {
+	check(exportCmd.Parse(args))
+	sites = filterSites(sites, splitList(*exportSiteFlag))
+
+	snap, err := takeSnapshot(uni, sites)
+	if err != nil {
+		log.Fatalln("Error:", err)
+	}
+	networks, err := uni.GetNetworks(sites)
+	if err != nil {
+		log.Fatalln("Error:", err)
+	}
+
+	if filepath.Ext(*exportOutputFlag) == ".sqlite" {
+		run, err := writeSQLite(*exportOutputFlag, snap, buildNetworks(sites, networks))
+		if err != nil {
+			log.Fatalln("Error:", err)
+		}
+		fmt.Printf("run %d: ", run)
+	} else {
+		f := excelize.NewFile()
+		check(exportWorkbook(f, snap, buildNetworks(sites, networks), *exportPerSiteFlag))
+		if err := f.SaveAs(*exportOutputFlag); err != nil {
+			log.Fatalln(err)
+		}
+	}
+	fmt.Printf("wrote %d clients, %d devices, %d ports and %d networks to %s\n",
+		len(snap.Clients), len(snap.Devices), len(snap.Ports), len(networks), *exportOutputFlag)
+}
+
+// exportWorkbook writes everything in snap and networks to
+// separate sheets of f and optionally a clients sheet per site.
+func exportWorkbook(f *excelize.File, snap *snapshot, networks []*NetworkInfo, perSite bool) error {
+	if err := f.SetSheetName(f.GetSheetName(0), "Clients"); err != nil {
+		return err
+	}
+	if err := clientSheet(f, "Clients", snap.Clients); err != nil {
+		return err
+	}
+
+	for _, name := range []string{"Devices", "Ports", "Sites", "Networks"} {
+		if _, err := f.NewSheet(name); err != nil {
+			return err
+		}
+	}
+	if err := devicesSheet(f, "Devices", snap.Devices); err != nil {
+		return err
+	}
+	if err := writeSheet(f, "Ports", port_fields, len(snap.Ports), portCells(snap.Ports), portStyle); err != nil {
+		return err
+	}
+	siteinfo := buildSites(snap.Sites, snap.Clients, snap.Devices)
+	if err := writeSheet(f, "Sites", site_fields, len(siteinfo), siteCells(siteinfo), sheetStyle{}); err != nil {
+		return err
+	}
+	countNetworkClients(networks, snap.Clients)
+	if err := writeSheet(f, "Networks", network_fields, len(networks), networkCells(networks), sheetStyle{}); err != nil {
+		return err
+	}
+
+	if !perSite {
+		return nil
+	}
+	used := make(map[string]bool)
+	for _, name := range f.GetSheetList() {
+		used[strings.ToLower(name)] = true
+	}
+	for _, site := range snap.Sites {
+		var clients []*unifi.Client
+		for _, c := range snap.Clients {
+			if c.SiteName == site.SiteName {
+				clients = append(clients, c)
+			}
+		}
+		desc := site.Desc
+		if desc == "" {
+			desc = site.Name
+		}
+		sname := sheetName(desc, used)
+		if _, err := f.NewSheet(sname); err != nil {
+			return err
+		}
+		if err := clientSheet(f, sname, clients); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sheetName makes name valid and unique as an Excel sheet name.
+// Sheet names are at most 31 characters, can not contain any
+// of []:*?/\ and are compared case insensitive.
+func sheetName(name string, used map[string]bool) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	name = strings.Trim(name, "' ")
+	if name == "" {
+		name = "Site"
+	}
+	candidate := truncateRunes(name, 31)
+	for i := 2; used[strings.ToLower(candidate)]; i++ {
+		suffix := fmt.Sprintf(" (%d)", i)
+		candidate = truncateRunes(name, 31-utf8.RuneCountInString(suffix)) + suffix
+	}
+	used[strings.ToLower(candidate)] = true
+	return candidate
+}
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
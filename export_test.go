@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func Test_sheetName(t *testing.T) {
+	used := map[string]bool{"clients": true}
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Office", "Office"},
+		{"office", "office (2)"},
+		{"Clients", "Clients (2)"},
+		{"HQ: floor 1/2", "HQ_ floor 1_2"},
+		{strings.Repeat("x", 40), strings.Repeat("x", 31)},
+		{strings.Repeat("x", 40), strings.Repeat("x", 27) + " (2)"},
+		{"''", "Site"},
+	}
+	for _, tt := range tests {
+		if got := sheetName(tt.name, used); got != tt.want {
+			t.Errorf("sheetName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func Test_exportWorkbook(t *testing.T) {
+	snap := testPoller().snap
+	snap.Clients[0].Network = "LAN"
+	f := excelize.NewFile()
+	networks := []*NetworkInfo{{Site: "Office (default)", Name: "LAN", VLAN: 10}}
+	if err := exportWorkbook(f, snap, networks, true); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Clients", "Devices", "Ports", "Sites", "Networks", "Office", "Warehouse"}
+	if got := f.GetSheetList(); !reflect.DeepEqual(got, want) {
+		t.Errorf("sheets = %v, want %v", got, want)
+	}
+	rows, err := f.GetRows("Warehouse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[1][0] != "66:77:88:99:aa:bb" {
+		t.Errorf("warehouse rows = %v", rows)
+	}
+	rows, _ = f.GetRows("Sites")
+	if len(rows) != 3 || rows[1][3] != "1" {
+		t.Errorf("sites rows = %v", rows)
+	}
+}
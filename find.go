@@ -0,0 +1,242 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/unpoller/unifi"
+)
+
+var (
+	findCmd       = flag.NewFlagSet("find", flag.ExitOnError)
+	findSiteFlag  = findCmd.String("site", "", "comma separated list of sites to search")
+	findHoursFlag = findCmd.Int("hours", 24*30, "include known clients seen within this many hours")
+)
+
+// findResult collects what matched a search.
+type findResult struct {
+	Clients []*unifi.Client
+	Users   []*unifi.User
+	Devices []*Device
+}
+
+func findRun(uni *unifi.Unifi, sites []*unifi.Site, args []string) {
+	check(findCmd.Parse(args))
+	if findCmd.NArg() != 1 {
+		log.Fatalln("usage: unimac find [flags] <mac|ip|hostname|partial>")
+	}
+	term := findCmd.Arg(0)
+
+	sites = filterSites(sites, splitList(*findSiteFlag))
+	clients, err := uni.GetClients(sites)
+	if err != nil {
+		log.Fatalln("Error:", err)
+	}
+	users, err := uni.GetUsers(sites, *findHoursFlag)
+	if err != nil {
+		log.Fatalln("Error:", err)
+	}
+	unifidevices, err := uni.GetDevices(sites)
+	if err != nil {
+		log.Fatalln("Error:", err)
+	}
+	networks, err := uni.GetNetworks(sites)
+	if err != nil {
+		log.Fatalln("Error:", err)
+	}
+	hydrateClients(clients, unifidevices)
+	devices := buildDevices(unifidevices)
+
+	result := findMatches(term, clients, users, devices)
+	total := len(result.Clients) + len(result.Users) + len(result.Devices)
+	if total == 0 {
+		fmt.Printf("nothing found matching '%s'\n", term)
+		os.Exit(1)
+	}
+	fmt.Printf("%d matches for '%s'\n", total, term)
+	check(printFindResult(os.Stdout, result, devices, networks))
+}
+
+var macSeparators = strings.NewReplacer("-", "", ":", "", ".", "")
+
+// isHex reports if s only contains hexadecimal digits.
+func isHex(s string) bool {
+	return strings.Trim(s, "0123456789abcdef") == ""
+}
+
+// macShaped reports if term is a whole or partial MAC, groups of two hex
+// digits separated by ':' or '-', groups of four separated by '.' or
+// all 12 digits without separators. Words like "cafe" and addresses
+// like "10.0.0.1" are not.
+func macShaped(term string) bool {
+	if len(macSeparators.Replace(term)) > 12 {
+		return false
+	}
+	for _, s := range []struct {
+		sep  string
+		size int
+	}{{":", 2}, {"-", 2}, {".", 4}} {
+		if groups := strings.Split(term, s.sep); len(groups) > 1 {
+			for _, g := range groups {
+				if len(g) != s.size || !isHex(g) {
+					return false
+				}
+			}
+			return true
+		}
+	}
+	return len(term) == 12 && isHex(term)
+}
+
+// matchesTerm reports if mac or any of values matches the search term.
+// MAC shaped terms are compared without separators so that partial
+// MACs in any notation match, everything else as case insensitive
+// substrings.
+func matchesTerm(term string, mac string, values ...string) bool {
+	term = strings.ToLower(strings.TrimSpace(term))
+	if term == "" {
+		return false
+	}
+	if bare := macSeparators.Replace(term); macShaped(term) {
+		if strings.Contains(macSeparators.Replace(strings.ToLower(mac)), bare) {
+			return true
+		}
+	}
+	for _, v := range append(values, mac) {
+		if v != "" && strings.Contains(strings.ToLower(v), term) {
+			return true
+		}
+	}
+	return false
+}
+
+// findMatches searches active clients, known users and devices.
+// Known users that are also active clients are left out.
+func findMatches(term string, clients []*unifi.Client, users []*unifi.User, devices []*Device) *findResult {
+	result := &findResult{}
+	active := make(map[string]bool)
+	for _, c := range clients {
+		if matchesTerm(term, c.Mac, c.IP, c.Hostname, c.Name) {
+			result.Clients = append(result.Clients, c)
+			active[c.Mac] = true
+		}
+	}
+	for _, u := range users {
+		if !active[u.Mac] && matchesTerm(term, u.Mac, u.FixedIP, u.Hostname, u.Name) {
+			result.Users = append(result.Users, u)
+		}
+	}
+	for _, d := range devices {
+		if matchesTerm(term, d.Mac, d.IP, d.Name) {
+			result.Devices = append(result.Devices, d)
+		}
+	}
+	return result
+}
+
+// isGateway reports if d is a gateway type of device.
+func isGateway(d *Device) bool {
+	return d.Type == "USG" || d.Type == "UXG" || d.Type == "UDM"
+}
+
+// uplinkChain starts with the device with mac and follows the
+// uplinks, with the port used on each upstream device, until a
+// gateway or an unknown device is reached.
+func uplinkChain(devices []*Device, mac string) []string {
+	devicemap := make(map[string]*Device)
+	for _, d := range devices {
+		devicemap[d.Mac] = d
+	}
+	d, ok := devicemap[mac]
+	if !ok {
+		return []string{mac}
+	}
+	chain := []string{d.Displayname()}
+	visited := map[string]bool{mac: true}
+	for !isGateway(d) && d.Uplink != nil && d.Uplink.Mac != "" && !visited[d.Uplink.Mac] {
+		visited[d.Uplink.Mac] = true
+		up, ok := devicemap[d.Uplink.Mac]
+		name := d.Uplink.Displayname()
+		if ok {
+			name = up.Displayname()
+		}
+		if d.Uplink.Port != "" {
+			name += " port " + d.Uplink.Port
+		}
+		chain = append(chain, name)
+		if !ok {
+			break
+		}
+		d = up
+	}
+	return chain
+}
+
+// networkName returns the network with its VLAN if one is known.
+func networkName(networks []unifi.Network, siteID, name string) string {
+	for _, n := range networks {
+		if n.SiteID == siteID && n.Name == name && n.Vlan.Val > 0 {
+			return fmt.Sprintf("%s (VLAN %d)", name, int(n.Vlan.Val))
+		}
+	}
+	return name
+}
+
+func formatUnix(ts float64) string {
+	if ts == 0 {
+		return ""
+	}
+	return time.Unix(int64(ts), 0).Format("2006-01-02 15:04:05")
+}
+
+func printFindResult(out io.Writer, result *findResult, devices []*Device, networks []unifi.Network) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, c := range result.Clients {
+		fmt.Fprintf(w, "\nClient %s\n", c.Mac)
+		fmt.Fprintf(w, "  Site:\t%s\n", c.SiteName)
+		fmt.Fprintf(w, "  IP:\t%s\n", c.IP)
+		fmt.Fprintf(w, "  Hostname:\t%s\n", c.Hostname)
+		fmt.Fprintf(w, "  Name:\t%s\n", c.Name)
+		fmt.Fprintf(w, "  Network:\t%s\n", networkName(networks, c.SiteID, c.Network))
+		var chain []string
+		if c.SwMac != "" {
+			fmt.Fprintf(w, "  Switch:\t%s port %s\n", c.SwName, c.SwPort.String())
+			chain = append([]string{fmt.Sprintf("%s port %s", c.SwName, c.SwPort.String())}, uplinkChain(devices, c.SwMac)[1:]...)
+		}
+		if c.ApMac != "" {
+			fmt.Fprintf(w, "  AP:\t%s RSSI %s\n", c.ApName, c.Rssi.String())
+			chain = uplinkChain(devices, c.ApMac)
+		}
+		fmt.Fprintf(w, "  Last seen:\t%s\n", formatUnix(c.LastSeen.Val))
+		fmt.Fprintf(w, "  Note:\t%s\n", c.Note)
+		fmt.Fprintf(w, "  Uplink:\t%s\n", strings.Join(chain, " -> "))
+	}
+	for _, u := range result.Users {
+		fmt.Fprintf(w, "\nKnown client %s (not connected)\n", u.Mac)
+		fmt.Fprintf(w, "  Site:\t%s\n", u.SiteName)
+		fmt.Fprintf(w, "  Fixed IP:\t%s\n", u.FixedIP)
+		fmt.Fprintf(w, "  Hostname:\t%s\n", u.Hostname)
+		fmt.Fprintf(w, "  Name:\t%s\n", u.Name)
+		fmt.Fprintf(w, "  First seen:\t%s\n", formatUnix(u.FirstSeen.Val))
+		fmt.Fprintf(w, "  Last seen:\t%s\n", formatUnix(u.LastSeen.Val))
+		fmt.Fprintf(w, "  Note:\t%s\n", u.Note)
+	}
+	for _, d := range result.Devices {
+		fmt.Fprintf(w, "\nDevice %s (%s)\n", d.Mac, d.Type)
+		fmt.Fprintf(w, "  Site:\t%s\n", d.Site)
+		fmt.Fprintf(w, "  IP:\t%s\n", d.IP)
+		fmt.Fprintf(w, "  Name:\t%s\n", d.Name)
+		fmt.Fprintf(w, "  Uplink:\t%s\n", strings.Join(uplinkChain(devices, d.Mac), " -> "))
+	}
+	return w.Flush()
+}
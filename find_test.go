@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_matchesTerm(t *testing.T) {
+	tests := []struct {
+		term   string
+		mac    string
+		values []string
+		want   bool
+	}{
+		{"00:11:22:33:44:55", "00:11:22:33:44:55", nil, true},
+		{"0011.2233.4455", "00:11:22:33:44:55", nil, true},
+		{"33-44", "00:11:22:33:44:55", nil, true},
+		{"001122334455", "00:11:22:33:44:55", nil, true},
+		{"10.0.0.1", "00:11:00:01:aa:bb", []string{"10.0.0.2"}, false},
+		{"cafe", "00:11:ca:fe:44:55", nil, false},
+		{"10.0.0", "00:11:22:33:44:55", []string{"10.0.0.2"}, true},
+		{"LAPTOP", "00:11:22:33:44:55", []string{"10.0.0.2", "laptop-01"}, true},
+		{"printer", "00:11:22:33:44:55", []string{"10.0.0.2", "laptop-01"}, false},
+		{"", "00:11:22:33:44:55", nil, false},
+	}
+	for _, tt := range tests {
+		if got := matchesTerm(tt.term, tt.mac, tt.values...); got != tt.want {
+			t.Errorf("matchesTerm(%q, %q, %v) = %v, want %v", tt.term, tt.mac, tt.values, got, tt.want)
+		}
+	}
+}
+
+func Test_uplinkChain(t *testing.T) {
+	devices := []*Device{
+		{Mac: "gw", Name: "gateway", Type: "USG", Uplink: &DevicePort{Mac: "isp", Port: "1"}},
+		{Mac: "core", Name: "sw-core", Type: "USW", Uplink: &DevicePort{Mac: "gw", Port: "2"}},
+		{Mac: "access", Name: "sw-access", Type: "USW", Uplink: &DevicePort{Mac: "core", Name: "sw-core", Port: "24"}},
+		{Mac: "ap", Name: "ap-lobby", Type: "UAP", Uplink: &DevicePort{Mac: "access", Name: "sw-access", Port: "5"}},
+		{Mac: "loop", Name: "loop", Type: "USW", Uplink: &DevicePort{Mac: "loop", Port: "1"}},
+	}
+	tests := []struct {
+		mac  string
+		want []string
+	}{
+		{"ap", []string{"ap-lobby", "sw-access port 5", "sw-core port 24", "gateway port 2"}},
+		{"gw", []string{"gateway"}},
+		{"loop", []string{"loop"}},
+		{"unknown", []string{"unknown"}},
+	}
+	for _, tt := range tests {
+		if got := uplinkChain(devices, tt.mac); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("uplinkChain(%s) = %v, want %v", tt.mac, got, tt.want)
+		}
+	}
+}
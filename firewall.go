@@ -0,0 +1,302 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/netip"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/unpoller/unifi"
+)
+
+// firewallEntry is the address of a client with a comment naming it.
+type firewallEntry struct {
+	ip      netip.Addr
+	comment string
+}
+
+// firewallGroup is a named set of addresses.
+type firewallGroup struct {
+	name    string
+	entries []firewallEntry
+	seen    map[netip.Addr]bool
+}
+
+// buildFirewallGroups groups the IPv4 addresses of clients by their
+// network or by the #tags in their note.
+// Clients without an address or anything to group by are left out and
+// an address is only added once to a group, the first client names it.
+// Different names that are shortened to the same alias are an error.
+func buildFirewallGroups(clients []*unifi.Client, by string) ([]*firewallGroup, error) {
+	var tags func(*unifi.Client) []string
+	switch strings.ToLower(by) {
+	case "network":
+		tags = func(c *unifi.Client) []string { return []string{c.Network} }
+	case "note":
+		tags = func(c *unifi.Client) []string { return noteTags(c.Note) }
+	default:
+		return nil, fmt.Errorf("unknown group '%s', use network or note", by)
+	}
+
+	groups := make(map[string]*firewallGroup)
+	sources := make(map[string]string)
+	for _, c := range clients {
+		ip, err := netip.ParseAddr(c.IP)
+		if err != nil || !ip.Is4() {
+			continue
+		}
+		comment := c.Name
+		if comment == "" {
+			comment = c.Hostname
+		}
+		if comment == "" {
+			comment = c.Mac
+		}
+		for _, tag := range tags(c) {
+			if strings.TrimSpace(tag) == "" {
+				continue
+			}
+			name := aliasName(tag)
+			if source, ok := sources[name]; ok && source != cleanName(tag) {
+				return nil, fmt.Errorf("'%s' and '%s' are both the alias %s", source, cleanName(tag), name)
+			}
+			sources[name] = cleanName(tag)
+			g, ok := groups[name]
+			if !ok {
+				g = &firewallGroup{name: name, seen: make(map[netip.Addr]bool)}
+				groups[name] = g
+			}
+			if g.seen[ip] {
+				continue
+			}
+			g.seen[ip] = true
+			g.entries = append(g.entries, firewallEntry{ip, comment})
+		}
+	}
+
+	result := make([]*firewallGroup, 0, len(groups))
+	for _, g := range groups {
+		sort.Slice(g.entries, func(i, j int) bool { return g.entries[i].ip.Less(g.entries[j].ip) })
+		result = append(result, g)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].name < result[j].name })
+	return result, nil
+}
+
+// noteTags returns the words of note that start with #, without it.
+func noteTags(note string) []string {
+	var tags []string
+	for _, word := range strings.FieldsFunc(note, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	}) {
+		if len(word) > 1 && word[0] == '#' {
+			tags = append(tags, word[1:])
+		}
+	}
+	return tags
+}
+
+// cleanName is s in lower case with anything but letters and digits
+// replaced by single underscores, not starting with a digit.
+func cleanName(s string) string {
+	name := strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	}), "_")
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		name = "net_" + name
+	}
+	return name
+}
+
+// aliasName makes s a name that pfSense, nftables and ipset accept,
+// at most 31 letters, digits and underscores not starting with a digit.
+func aliasName(s string) string {
+	return strings.TrimRight(truncateRunes(cleanName(s), 31), "_")
+}
+
+// pfAliases is the aliases section of a pfSense configuration,
+// which can be restored on its own.
+type pfAliases struct {
+	XMLName xml.Name  `xml:"aliases"`
+	Aliases []pfAlias `xml:"alias"`
+}
+
+type pfAlias struct {
+	Name    string `xml:"name"`
+	Type    string `xml:"type"`
+	Address string `xml:"address"`
+	Descr   string `xml:"descr"`
+	Detail  string `xml:"detail"`
+}
+
+// writePfSense outputs the groups as pfSense host aliases.
+func writePfSense(out io.Writer, groups []*firewallGroup) error {
+	aliases := pfAliases{Aliases: []pfAlias{}}
+	for _, g := range groups {
+		ips := make([]string, len(g.entries))
+		details := make([]string, len(g.entries))
+		for i, e := range g.entries {
+			ips[i] = e.ip.String()
+			details[i] = strings.ReplaceAll(e.comment, "||", "|")
+		}
+		aliases.Aliases = append(aliases.Aliases, pfAlias{
+			Name:    g.name,
+			Type:    "host",
+			Address: strings.Join(ips, " "),
+			Descr:   "unimac",
+			Detail:  strings.Join(details, "||"),
+		})
+	}
+	if _, err := io.WriteString(out, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(out)
+	enc.Indent("", "\t")
+	if err := enc.Encode(aliases); err != nil {
+		return err
+	}
+	_, err := io.WriteString(out, "\n")
+	return err
+}
+
+// opnAliases is the alias model of an OPNsense configuration.
+type opnAliases struct {
+	XMLName xml.Name   `xml:"OPNsense"`
+	Aliases []opnAlias `xml:"Firewall>Alias>aliases>alias"`
+}
+
+type opnAlias struct {
+	UUID        string `xml:"uuid,attr"`
+	Enabled     int    `xml:"enabled"`
+	Name        string `xml:"name"`
+	Type        string `xml:"type"`
+	Content     string `xml:"content"`
+	Description string `xml:"description"`
+}
+
+// writeOPNsense outputs the groups as OPNsense host aliases. The uuid
+// comes from the name so an alias keeps it when exported again.
+func writeOPNsense(out io.Writer, groups []*firewallGroup) error {
+	aliases := opnAliases{Aliases: []opnAlias{}}
+	for _, g := range groups {
+		ips := make([]string, len(g.entries))
+		for i, e := range g.entries {
+			ips[i] = e.ip.String()
+		}
+		aliases.Aliases = append(aliases.Aliases, opnAlias{
+			UUID:        aliasUUID(g.name),
+			Enabled:     1,
+			Name:        g.name,
+			Type:        "host",
+			Content:     strings.Join(ips, "\n"),
+			Description: "unimac",
+		})
+	}
+	if _, err := io.WriteString(out, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(out)
+	enc.Indent("", "\t")
+	if err := enc.Encode(aliases); err != nil {
+		return err
+	}
+	_, err := io.WriteString(out, "\n")
+	return err
+}
+
+// aliasUUID returns a name based (version 5) uuid for an alias name.
+func aliasUUID(name string) string {
+	h := sha1.Sum([]byte("unimac alias " + name))
+	h[6] = h[6]&0x0f | 0x50
+	h[8] = h[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", h[0:4], h[4:6], h[6:8], h[8:10], h[10:16])
+}
+
+// writeNftables outputs the groups as nftables named sets,
+// to be included in a table.
+func writeNftables(out io.Writer, groups []*firewallGroup) error {
+	w := bufio.NewWriter(out)
+	for i, g := range groups {
+		if i > 0 {
+			w.WriteString("\n")
+		}
+		fmt.Fprintf(w, "set %s {\n", g.name)
+		fmt.Fprintf(w, "\ttype ipv4_addr\n")
+		fmt.Fprintf(w, "\telements = {\n")
+		for j, e := range g.entries {
+			sep := ","
+			if j == len(g.entries)-1 {
+				sep = ""
+			}
+			fmt.Fprintf(w, "\t\t%s%s\n", e.ip, sep)
+		}
+		fmt.Fprintf(w, "\t}\n")
+		fmt.Fprintf(w, "}\n")
+	}
+	return w.Flush()
+}
+
+// writeIpset outputs the groups for ipset restore. The sets are
+// created if they do not exist and emptied before they are filled.
+func writeIpset(out io.Writer, groups []*firewallGroup) error {
+	w := bufio.NewWriter(out)
+	for _, g := range groups {
+		fmt.Fprintf(w, "create %s hash:ip family inet -exist\n", g.name)
+		fmt.Fprintf(w, "flush %s\n", g.name)
+		for _, e := range g.entries {
+			fmt.Fprintf(w, "add %s %s -exist\n", g.name, e.ip)
+		}
+	}
+	return w.Flush()
+}
+
+// writeMikrotik outputs the groups as a RouterOS script that
+// replaces the address lists with the same names.
+func writeMikrotik(out io.Writer, groups []*firewallGroup) error {
+	quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`)
+	w := bufio.NewWriter(out)
+	fmt.Fprintf(w, "/ip firewall address-list\n")
+	for _, g := range groups {
+		fmt.Fprintf(w, "remove [find list=%s]\n", g.name)
+		for _, e := range g.entries {
+			fmt.Fprintf(w, "add list=%s address=%s comment=\"%s\"\n", g.name, e.ip, quote.Replace(e.comment))
+		}
+	}
+	return w.Flush()
+}
+
+// clientFirewall returns a renderer for the clients as address groups
+// for the firewall kind or nil if kind is not known.
+func clientFirewall(kind, by string) clientRender {
+	var write func(io.Writer, []*firewallGroup) error
+	switch strings.ToLower(kind) {
+	case "pfsense":
+		write = writePfSense
+	case "opnsense":
+		write = writeOPNsense
+	case "nftables", "nft":
+		write = writeNftables
+	case "ipset":
+		write = writeIpset
+	case "mikrotik", "routeros":
+		write = writeMikrotik
+	default:
+		return nil
+	}
+	return func(out io.Writer, clients []*unifi.Client, _ []string) error {
+		groups, err := buildFirewallGroups(clients, by)
+		if err != nil {
+			return err
+		}
+		return write(out, groups)
+	}
+}
@@ -0,0 +1,187 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/unpoller/unifi"
+)
+
+func testFirewallClients() []*unifi.Client {
+	return []*unifi.Client{
+		{Mac: "00:00:00:00:00:01", IP: "10.0.5.20", Network: "IoT", Name: "lamp", Note: "#lights, #iot #IoT"},
+		{Mac: "00:00:00:00:00:02", IP: "10.0.5.3", Network: "IoT", Hostname: "printer", Note: "Printer, room 4 #iot"},
+		{Mac: "00:00:00:00:00:03", IP: "10.0.0.9", Network: "LAN", Name: `say "$hi"`},
+		{Mac: "00:00:00:00:00:04", Network: "LAN"},
+		{Mac: "00:00:00:00:00:05", IP: "fe80::1", Network: "LAN"},
+		// the same network and address on another site
+		{Mac: "00:00:00:00:00:06", IP: "10.0.0.9", Network: "LAN", SiteName: "Warehouse (ab12cd34)"},
+	}
+}
+
+func Test_buildFirewallGroups(t *testing.T) {
+	groups, err := buildFirewallGroups(testFirewallClients(), "note")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, g := range groups {
+		var ips []string
+		for _, e := range g.entries {
+			ips = append(ips, e.ip.String())
+		}
+		got = append(got, g.name+"="+strings.Join(ips, " "))
+	}
+	if want := "iot=10.0.5.3 10.0.5.20,lights=10.0.5.20"; strings.Join(got, ",") != want {
+		t.Errorf("groups = %s, want %s", strings.Join(got, ","), want)
+	}
+	if _, err := buildFirewallGroups(nil, "vlan"); err == nil {
+		t.Error("expected error for unknown group")
+	}
+	long := []*unifi.Client{
+		{Mac: "00:00:00:00:00:01", IP: "10.0.0.1", Network: strings.Repeat("a", 31) + " office"},
+		{Mac: "00:00:00:00:00:02", IP: "10.0.0.2", Network: strings.Repeat("a", 31) + " guests"},
+	}
+	if _, err := buildFirewallGroups(long, "network"); err == nil {
+		t.Error("expected error for names that are the same alias")
+	}
+}
+
+func Test_clientFirewall(t *testing.T) {
+	tests := []struct {
+		kind string
+		want string
+	}{
+		{"pfsense", `<?xml version="1.0" encoding="UTF-8"?>
+<aliases>
+	<alias>
+		<name>iot</name>
+		<type>host</type>
+		<address>10.0.5.3 10.0.5.20</address>
+		<descr>unimac</descr>
+		<detail>printer||lamp</detail>
+	</alias>
+	<alias>
+		<name>lan</name>
+		<type>host</type>
+		<address>10.0.0.9</address>
+		<descr>unimac</descr>
+		<detail>say &#34;$hi&#34;</detail>
+	</alias>
+</aliases>
+`},
+		{"opnsense", `<?xml version="1.0" encoding="UTF-8"?>
+<OPNsense>
+	<Firewall>
+		<Alias>
+			<aliases>
+				<alias uuid="9cc9c5e1-0803-5984-9325-2c34d3a05ea8">
+					<enabled>1</enabled>
+					<name>iot</name>
+					<type>host</type>
+					<content>10.0.5.3&#xA;10.0.5.20</content>
+					<description>unimac</description>
+				</alias>
+				<alias uuid="c085d159-9a51-53e3-a903-8d70f5bef356">
+					<enabled>1</enabled>
+					<name>lan</name>
+					<type>host</type>
+					<content>10.0.0.9</content>
+					<description>unimac</description>
+				</alias>
+			</aliases>
+		</Alias>
+	</Firewall>
+</OPNsense>
+`},
+		{"nftables", `set iot {
+	type ipv4_addr
+	elements = {
+		10.0.5.3,
+		10.0.5.20
+	}
+}
+
+set lan {
+	type ipv4_addr
+	elements = {
+		10.0.0.9
+	}
+}
+`},
+		{"ipset", `create iot hash:ip family inet -exist
+flush iot
+add iot 10.0.5.3 -exist
+add iot 10.0.5.20 -exist
+create lan hash:ip family inet -exist
+flush lan
+add lan 10.0.0.9 -exist
+`},
+		{"mikrotik", `/ip firewall address-list
+remove [find list=iot]
+add list=iot address=10.0.5.3 comment="printer"
+add list=iot address=10.0.5.20 comment="lamp"
+remove [find list=lan]
+add list=lan address=10.0.0.9 comment="say \"\$hi\""
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := clientFirewall(tt.kind, "network")(&buf, testFirewallClients(), nil); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+	if clientFirewall("iptables", "network") != nil {
+		t.Error("expected no renderer for iptables")
+	}
+}
+
+func Test_aliasName(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{"lower case", "IoT", "iot"},
+		{"spaces", " Guest WiFi ", "guest_wifi"},
+		{"digit first", "10.0.0.0/24", "net_10_0_0_0_24"},
+		{"empty", "", "net"},
+		{"too long", strings.Repeat("a", 40), strings.Repeat("a", 31)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := aliasName(tt.s); got != tt.want {
+				t.Errorf("aliasName(%q) = %q, want %q", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_noteTags(t *testing.T) {
+	tests := []struct {
+		name string
+		note string
+		want string
+	}{
+		{"free text", "Printer, room 4", ""},
+		{"tags", "#iot,#lights  #cameras", "iot lights cameras"},
+		{"in text", "Printer in room 4 #iot", "iot"},
+		{"lone hash", "# not a tag", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.Join(noteTags(tt.note), " "); got != tt.want {
+				t.Errorf("noteTags(%q) = %q, want %q", tt.note, got, tt.want)
+			}
+		})
+	}
+}
@@ -3,8 +3,10 @@ module github.com/kmpm/unimac
 go 1.19
 
 require (
+	github.com/mattn/go-sqlite3 v1.14.22
 	github.com/unpoller/unifi v0.3.14
 	github.com/xuri/excelize/v2 v2.7.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,86 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"html/template"
+	"io"
+)
+
+// tableTemplate is a self contained html page with a table
+// that is sorted by clicking the column headers.
+var tableTemplate = template.Must(template.New("table").Parse(`
+{{- define "head"}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.}}</title>
+<style>
+body { font-family: sans-serif; margin: 1em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.25em 0.5em; text-align: left; }
+th { background: #f0f0f0; cursor: pointer; user-select: none; }
+th.asc::after { content: " \25B2"; }
+th.desc::after { content: " \25BC"; }
+tr:nth-child(even) td { background: #fafafa; }
+</style>
+</head>
+<body>
+<h2>{{.}}</h2>
+<table>
+<thead><tr>
+{{- end}}
+{{- define "header"}}<th>{{.}}</th>{{end}}
+{{- define "body"}}</tr></thead>
+<tbody>
+{{end}}
+{{- define "row"}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}
+{{- define "foot"}}</tbody>
+</table>
+<script>
+document.querySelectorAll("th").forEach(function (th, col) {
+  th.onclick = function () {
+    var desc = th.className === "asc";
+    document.querySelectorAll("th").forEach(function (h) { h.className = ""; });
+    th.className = desc ? "desc" : "asc";
+    var tbody = document.querySelector("tbody");
+    var rows = Array.prototype.slice.call(tbody.rows);
+    rows.sort(function (a, b) {
+      var c = a.cells[col].textContent.localeCompare(b.cells[col].textContent, undefined, { numeric: true });
+      return desc ? -c : c;
+    });
+    rows.forEach(function (r) { tbody.appendChild(r); });
+  };
+});
+</script>
+</body>
+</html>
+{{end}}`))
+
+// writeHTML outputs n rows as a sortable html table with fields as header.
+func writeHTML(out io.Writer, title string, fields []string, n int, value rowValue) error {
+	if err := tableTemplate.ExecuteTemplate(out, "head", title); err != nil {
+		return err
+	}
+	for _, field := range fields {
+		if err := tableTemplate.ExecuteTemplate(out, "header", field); err != nil {
+			return err
+		}
+	}
+	if err := tableTemplate.ExecuteTemplate(out, "body", nil); err != nil {
+		return err
+	}
+	record := make([]string, len(fields))
+	for row := 0; row < n; row++ {
+		for i, field := range fields {
+			record[i] = value(row, field)
+		}
+		if err := tableTemplate.ExecuteTemplate(out, "row", record); err != nil {
+			return err
+		}
+	}
+	return tableTemplate.ExecuteTemplate(out, "foot", nil)
+}
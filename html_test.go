@@ -0,0 +1,29 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_writeHTML(t *testing.T) {
+	devices := []*Device{{Mac: "00:11:22:33:44:55", Name: "<script>", State: "online"}}
+	var buf bytes.Buffer
+	if err := devicesHTML(&buf, devices, []string{DEVICE_MAC, DEVICE_NAME}); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"<title>Devices</title>",
+		"<th>MAC</th><th>Name</th>",
+		"<tr><td>00:11:22:33:44:55</td><td>&lt;script&gt;</td></tr>",
+		"</html>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in\n%s", want, got)
+		}
+	}
+}
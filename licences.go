@@ -65,6 +65,29 @@ modification, are permitted provided that the following conditions are met:
 
 }
 
+func printApache2(title string, copyrights ...string) {
+	text := `Apache License, Version 2.0
+%s
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+`
+	var t string
+	for _, c := range copyrights {
+		t += fmt.Sprintf("Copyright (c) %s\n", c)
+	}
+	fmt.Printf("\n\n-- %s --\n", title)
+	fmt.Printf(text, t)
+}
+
 func printDisclaimer(prefix, postfix string) {
 	text := `THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
 EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
@@ -86,4 +109,7 @@ func licensesRun(arguments []string) {
 	fmt.Println("-- direct dependencies --")
 	printMIT("github.com/unpoller/unifi", "2018-2020 David Newhall II", "2016 Garrett Bjerkhoel")
 	printBSD3("github.com/qax-os/excelize", "2016-2022 The excelize Authors.")
+	printMIT("github.com/mattn/go-sqlite3", "2014 Yasuhiro Matsumoto")
+	printApache2("gopkg.in/yaml.v3", "2011-2016 Canonical Ltd.")
+	printMIT("gopkg.in/yaml.v3 (ported from libyaml)", "2006-2011 Kirill Simonov")
 }
@@ -62,6 +62,45 @@ func main() {
 		uni, sites := mustConnect()
 		check(clientsCmd.Parse(args[1:]))
 		generateClients(uni, sites)
+	case "ports":
+		uni, sites := mustConnect()
+		check(portsCmd.Parse(args[1:]))
+		generatePorts(uni, sites)
+	case "sites":
+		uni, sites := mustConnect()
+		check(sitesCmd.Parse(args[1:]))
+		generateSites(uni, sites)
+	case "networks":
+		uni, sites := mustConnect()
+		check(networksCmd.Parse(args[1:]))
+		generateNetworks(uni, sites)
+	case "find":
+		uni, sites := mustConnect()
+		findRun(uni, sites, args[1:])
+	case "watch":
+		uni, sites := mustConnect()
+		watchRun(uni, sites, args[1:])
+	case "report":
+		uni, sites := mustConnect()
+		reportRun(uni, sites, args[1:])
+	case "export":
+		uni, sites := mustConnect()
+		exportRun(uni, sites, args[1:])
+	case "netbox":
+		uni, sites := mustConnect()
+		netboxRun(uni, sites, args[1:])
+	case "wake":
+		uni, sites := mustConnect()
+		wakeRun(uni, sites, args[1:])
+	case "daemon":
+		uni, sites := mustConnect()
+		daemonRun(uni, sites, args[1:])
+	case "serve":
+		uni, sites := mustConnect()
+		check(serveCmd.Parse(args[1:]))
+		serveRun(uni, sites)
+	case "query":
+		queryRun(args[1:])
 	case "version":
 		versionRun(args[1:])
 	case "licenses":
@@ -77,13 +116,14 @@ func mustConnect() (*unifi.Unifi, []*unifi.Site) {
 	if err != nil {
 		log.Fatalln("Error:", err)
 	}
-	fmt.Println("Connected to ", *hostFlag)
+	// status goes to stderr to keep stdout clean for json streams
+	fmt.Fprintln(os.Stderr, "Connected to ", *hostFlag)
 
 	sites, err := uni.GetSites()
 	if err != nil {
 		log.Fatalln("Error:", err)
 	}
-	fmt.Println(len(sites), "Unifi Sites Found")
+	fmt.Fprintln(os.Stderr, len(sites), "Unifi Sites Found")
 
 	return uni, sites
 }
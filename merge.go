@@ -0,0 +1,241 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// MERGE_VANISHED is the column merge adds with the time a MAC
+// was first missing from the controller.
+const MERGE_VANISHED = "Vanished"
+
+// mergeResult counts what mergeSheet did.
+type mergeResult struct {
+	Updated  int
+	Added    int
+	Vanished int
+}
+
+func (r *mergeResult) String() string {
+	return fmt.Sprintf("%d updated, %d added and %d vanished", r.Updated, r.Added, r.Vanished)
+}
+
+// mergeFile merges into the first sheet of the existing workbook filename
+// and saves it. Columns not in fields are left as they are.
+func mergeFile(filename string, fields []string, n int, value rowValue, cell rowCell) (*mergeResult, error) {
+	f, err := excelize.OpenFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	result, err := mergeSheet(f, f.GetSheetName(0), fields, n, value, cell, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("merging %s: %w", filename, err)
+	}
+	return result, f.Save()
+}
+
+// canMerge tells if there is an existing workbook to merge into.
+func canMerge(filename string) bool {
+	_, err := os.Stat(filename)
+	return err == nil
+}
+
+// mergeSheet updates the rows in sname that have the same MAC as
+// one of the n items, inserts rows for new MACs at the top and sets
+// Vanished on rows whose MAC is no longer reported.
+// Columns are found by their header so users can add, remove and
+// reorder columns. Missing fields are added as new columns last.
+// New rows are inserted below the header so that they become part
+// of an existing table and get the style of the row below.
+func mergeSheet(f *excelize.File, sname string, fields []string, n int, value rowValue, cell rowCell, now time.Time) (*mergeResult, error) {
+	rows, err := f.GetRows(sname)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("sheet '%s' has no header", sname)
+	}
+	cols := make(map[string]int)
+	for i, name := range rows[0] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := cols[name]; !ok && name != "" {
+			cols[name] = i + 1
+		}
+	}
+	macCol, ok := cols[strings.ToLower(CLIENT_MAC)]
+	if !ok {
+		return nil, fmt.Errorf("sheet '%s' has no %s column", sname, CLIENT_MAC)
+	}
+	last := len(rows[0])
+	for _, field := range append(append([]string{}, fields...), MERGE_VANISHED) {
+		if _, ok := cols[strings.ToLower(field)]; ok {
+			continue
+		}
+		last++
+		cols[strings.ToLower(field)] = last
+		if err := setCell(f, sname, last, 1, field); err != nil {
+			return nil, err
+		}
+	}
+
+	existing := make(map[string]int)
+	for i, row := range rows[1:] {
+		if macCol > len(row) {
+			continue
+		}
+		mac := normalizeMac(row[macCol-1])
+		if _, ok := existing[mac]; !ok && mac != "" {
+			existing[mac] = i + 2
+		}
+	}
+
+	result := &mergeResult{}
+	seen := make(map[string]bool)
+	var added []int
+	for i := 0; i < n; i++ {
+		mac := normalizeMac(value(i, CLIENT_MAC))
+		if _, ok := existing[mac]; !ok && !seen[mac] {
+			added = append(added, i)
+		}
+		seen[mac] = true
+	}
+
+	if len(added) > 0 {
+		if err := f.InsertRows(sname, 2, len(added)); err != nil {
+			return nil, err
+		}
+		for mac, row := range existing {
+			existing[mac] = row + len(added)
+		}
+		if len(rows) > 1 {
+			if err := copyRowStyle(f, sname, len(added)+2, 2, len(added), last); err != nil {
+				return nil, err
+			}
+		}
+		if err := extendConditionalFormats(f, sname, len(added)); err != nil {
+			return nil, err
+		}
+	}
+
+	vanished := cols[strings.ToLower(MERGE_VANISHED)]
+	write := func(row, i int) error {
+		for _, field := range fields {
+			if err := setCell(f, sname, cols[strings.ToLower(field)], row, cell(i, field)); err != nil {
+				return err
+			}
+		}
+		return setCell(f, sname, vanished, row, nil)
+	}
+	for j, i := range added {
+		// the MAC is written even if it is not one of the fields
+		// so that the next merge finds the row
+		if err := setCell(f, sname, macCol, j+2, value(i, CLIENT_MAC)); err != nil {
+			return nil, err
+		}
+		if err := write(j+2, i); err != nil {
+			return nil, err
+		}
+		result.Added++
+	}
+	for i := 0; i < n; i++ {
+		row, ok := existing[normalizeMac(value(i, CLIENT_MAC))]
+		if !ok {
+			continue
+		}
+		if err := write(row, i); err != nil {
+			return nil, err
+		}
+		result.Updated++
+	}
+
+	for mac, row := range existing {
+		if seen[mac] {
+			continue
+		}
+		name, err := excelize.CoordinatesToCellName(vanished, row)
+		if err != nil {
+			return nil, err
+		}
+		if v, _ := f.GetCellValue(sname, name); v != "" {
+			continue
+		}
+		if err := f.SetCellValue(sname, name, now); err != nil {
+			return nil, err
+		}
+		result.Vanished++
+	}
+	return result, nil
+}
+
+func setCell(f *excelize.File, sname string, col, row int, value any) error {
+	name, err := excelize.CoordinatesToCellName(col, row)
+	if err != nil {
+		return err
+	}
+	return f.SetCellValue(sname, name, value)
+}
+
+// copyRowStyle gives n rows starting at row to the style of the cells in from.
+func copyRowStyle(f *excelize.File, sname string, from, row, n, cols int) error {
+	for col := 1; col <= cols; col++ {
+		name, err := excelize.CoordinatesToCellName(col, from)
+		if err != nil {
+			return err
+		}
+		style, err := f.GetCellStyle(sname, name)
+		if err != nil || style == 0 {
+			continue
+		}
+		first, _ := excelize.CoordinatesToCellName(col, row)
+		end, _ := excelize.CoordinatesToCellName(col, row+n-1)
+		if err := f.SetCellStyle(sname, first, end, style); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extendConditionalFormats makes conditional formats that start at
+// the first data row cover n rows more, excelize does not adjust
+// them when inserting rows.
+func extendConditionalFormats(f *excelize.File, sname string, n int) error {
+	formats, err := f.GetConditionalFormats(sname)
+	if err != nil {
+		return err
+	}
+	for ref, opts := range formats {
+		from, to, ok := strings.Cut(ref, ":")
+		if !ok || len(opts) == 0 {
+			continue
+		}
+		col1, row1, err := excelize.CellNameToCoordinates(from)
+		if err != nil || row1 != 2 {
+			continue
+		}
+		col2, row2, err := excelize.CellNameToCoordinates(to)
+		if err != nil {
+			continue
+		}
+		end, err := excelize.CoordinatesToCellName(col2, row2+n)
+		if err != nil {
+			return err
+		}
+		if err := f.UnsetConditionalFormat(sname, ref); err != nil {
+			return err
+		}
+		start, _ := excelize.CoordinatesToCellName(col1, row1)
+		if err := f.SetConditionalFormat(sname, start+":"+end, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
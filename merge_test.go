@@ -0,0 +1,111 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func Test_mergeSheet(t *testing.T) {
+	first := []*Device{
+		{Mac: "00:00:00:00:00:01", Name: "sw-1", State: "online"},
+		{Mac: "00:00:00:00:00:02", Name: "ap-1", State: "online"},
+	}
+	var buf bytes.Buffer
+	if err := devicesExcel(&buf, first, nil); err != nil {
+		t.Fatal(err)
+	}
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sname := f.GetSheetName(0)
+	// a column added by hand
+	check(f.SetCellValue(sname, "L1", "Asset"))
+	check(f.SetCellValue(sname, "L2", "A-100"))
+	check(f.SetCellValue(sname, "L3", "A-200"))
+
+	second := []*Device{
+		{Mac: "00-00-00-00-00-01", Name: "core", State: "offline"},
+		{Mac: "00:00:00:00:00:03", Name: "new", State: "online"},
+	}
+	now := time.Date(2026, 3, 4, 5, 6, 0, 0, time.UTC)
+	result, err := mergeSheet(f, sname, device_fields, len(second), deviceValues(second), deviceCells(second), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (mergeResult{Updated: 1, Added: 1, Vanished: 1}); *result != want {
+		t.Errorf("result = %+v, want %+v", *result, want)
+	}
+
+	rows, err := f.GetRows(sname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rows[0][len(rows[0])-1]; got != MERGE_VANISHED {
+		t.Errorf("last header = %q, want %q", got, MERGE_VANISHED)
+	}
+	byMac := make(map[string][]string)
+	for _, row := range rows[1:] {
+		byMac[row[0]] = append(row, make([]string, 13-len(row))...)
+	}
+	tests := []struct {
+		mac      string
+		name     string
+		asset    string
+		vanished bool
+	}{
+		{"00-00-00-00-00-01", "core", "A-100", false},
+		{"00:00:00:00:00:02", "ap-1", "A-200", true},
+		{"00:00:00:00:00:03", "new", "", false},
+	}
+	for _, tt := range tests {
+		row, ok := byMac[tt.mac]
+		if !ok {
+			t.Errorf("no row for %s in %v", tt.mac, rows)
+			continue
+		}
+		if row[4] != tt.name || row[11] != tt.asset || (row[12] != "") != tt.vanished {
+			t.Errorf("%s = %v, want name %s, asset %q and vanished %v", tt.mac, row, tt.name, tt.asset, tt.vanished)
+		}
+	}
+	if rows[1][0] != "00:00:00:00:00:03" {
+		t.Errorf("new MAC is not first: %v", rows[1])
+	}
+
+	// a row that vanished in an earlier merge is not counted again
+	result, err = mergeSheet(f, sname, device_fields, len(second), deviceValues(second), deviceCells(second), now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (mergeResult{Updated: 2}); *result != want {
+		t.Errorf("second result = %+v, want %+v", *result, want)
+	}
+}
+
+func Test_mergeSheet_withoutMac(t *testing.T) {
+	f := excelize.NewFile()
+	sname := f.GetSheetName(0)
+	check(f.SetCellValue(sname, "A1", CLIENT_MAC))
+	devices := []*Device{{Mac: "00:00:00:00:00:01", Name: "sw-1"}}
+	fields := []string{DEVICE_NAME}
+	now := time.Date(2026, 3, 4, 5, 6, 0, 0, time.UTC)
+
+	for _, want := range []mergeResult{{Added: 1}, {Updated: 1}} {
+		result, err := mergeSheet(f, sname, fields, len(devices), deviceValues(devices), deviceCells(devices), now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *result != want {
+			t.Errorf("result = %+v, want %+v", *result, want)
+		}
+	}
+	if got, _ := f.GetCellValue(sname, "A2"); got != "00:00:00:00:00:01" {
+		t.Errorf("MAC of added row = %q", got)
+	}
+}
@@ -0,0 +1,165 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// monitorTemplate is the host template every host uses, it is
+// part of the sample configuration of both Icinga 2 and Nagios.
+const monitorTemplate = "generic-host"
+
+// monitorHost is a device as a host to monitor.
+type monitorHost struct {
+	device *Device
+	name   string
+	parent string
+	group  string
+}
+
+// monitorGroup is the hosts of a site.
+type monitorGroup struct {
+	name  string
+	alias string
+}
+
+// buildMonitorHosts names the devices and resolves their uplinks to the
+// host they depend on, so a device behind one that is down is seen as
+// unreachable instead of down.
+// Devices without an address can not be checked and are left out, with
+// their children depending on the closest host above them instead.
+func buildMonitorHosts(devices []*Device) ([]*monitorHost, []*monitorGroup) {
+	used := make(map[string]bool)
+	names := make(map[string]string)
+	bymac := make(map[string]*Device)
+	hosts := make([]*monitorHost, 0, len(devices))
+	for _, d := range devices {
+		bymac[d.Mac] = d
+		// named before skipping to keep the names of the ansible inventory
+		name := hostName(d, used)
+		if d.IP == "" {
+			log.Printf("Skipping %s, the controller reports no address for it", name)
+			continue
+		}
+		hosts = append(hosts, &monitorHost{device: d, name: name, group: groupName(d.Site)})
+		names[d.Mac] = name
+	}
+	aliases := make(map[string]string)
+	for _, h := range hosts {
+		h.parent = monitorParent(h.device, bymac, names)
+		aliases[h.group] = h.device.Site
+	}
+	groups := make([]*monitorGroup, 0, len(aliases))
+	for name, alias := range aliases {
+		groups = append(groups, &monitorGroup{name, alias})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].name < groups[j].name })
+	return hosts, groups
+}
+
+// monitorParent follows the uplinks of d to the first device
+// that is a host and returns its name, or "" if there is none.
+func monitorParent(d *Device, devices map[string]*Device, names map[string]string) string {
+	seen := map[string]bool{d.Mac: true}
+	for up := d.Uplink; up != nil && !seen[up.Mac]; {
+		if name, ok := names[up.Mac]; ok {
+			return name
+		}
+		seen[up.Mac] = true
+		next, ok := devices[up.Mac]
+		if !ok {
+			break
+		}
+		up = next.Uplink
+	}
+	return ""
+}
+
+// writeNagios outputs the devices as Nagios host objects with a
+// hostgroup per site and the uplink as parent.
+func writeNagios(out io.Writer, devices []*Device) error {
+	hosts, groups := buildMonitorHosts(devices)
+	w := bufio.NewWriter(out)
+	for _, g := range groups {
+		fmt.Fprintf(w, "define hostgroup {\n")
+		fmt.Fprintf(w, "    hostgroup_name  %s\n", g.name)
+		fmt.Fprintf(w, "    alias           %s\n", g.alias)
+		fmt.Fprintf(w, "}\n\n")
+	}
+	for _, h := range hosts {
+		d := h.device
+		fmt.Fprintf(w, "define host {\n")
+		fmt.Fprintf(w, "    use             %s\n", monitorTemplate)
+		fmt.Fprintf(w, "    host_name       %s\n", h.name)
+		fmt.Fprintf(w, "    alias           %s\n", d.Displayname())
+		fmt.Fprintf(w, "    address         %s\n", d.IP)
+		if h.parent != "" {
+			fmt.Fprintf(w, "    parents         %s\n", h.parent)
+		}
+		fmt.Fprintf(w, "    hostgroups      %s\n", h.group)
+		fmt.Fprintf(w, "    notes           %s\n", strings.Join(strings.Fields(d.Type+" "+d.Model+" "+d.Mac), " "))
+		fmt.Fprintf(w, "}\n\n")
+	}
+	return w.Flush()
+}
+
+// writeIcinga outputs the devices as Icinga 2 host objects with a
+// host group per site and a dependency on the uplink.
+func writeIcinga(out io.Writer, devices []*Device) error {
+	hosts, groups := buildMonitorHosts(devices)
+	w := bufio.NewWriter(out)
+	for _, g := range groups {
+		fmt.Fprintf(w, "object HostGroup %s {\n", strconv.Quote(g.name))
+		fmt.Fprintf(w, "  display_name = %s\n", strconv.Quote(g.alias))
+		fmt.Fprintf(w, "}\n\n")
+	}
+	for _, h := range hosts {
+		d := h.device
+		fmt.Fprintf(w, "object Host %s {\n", strconv.Quote(h.name))
+		fmt.Fprintf(w, "  import %s\n", strconv.Quote(monitorTemplate))
+		fmt.Fprintf(w, "  address = %s\n", strconv.Quote(d.IP))
+		fmt.Fprintf(w, "  display_name = %s\n", strconv.Quote(d.Displayname()))
+		fmt.Fprintf(w, "  groups = [ %s ]\n", strconv.Quote(h.group))
+		fmt.Fprintf(w, "  vars.mac = %s\n", strconv.Quote(d.Mac))
+		fmt.Fprintf(w, "  vars.type = %s\n", strconv.Quote(d.Type))
+		if d.Model != "" {
+			fmt.Fprintf(w, "  vars.model = %s\n", strconv.Quote(d.Model))
+		}
+		fmt.Fprintf(w, "}\n\n")
+		if h.parent != "" {
+			fmt.Fprintf(w, "object Dependency \"uplink\" {\n")
+			fmt.Fprintf(w, "  parent_host_name = %s\n", strconv.Quote(h.parent))
+			fmt.Fprintf(w, "  child_host_name = %s\n", strconv.Quote(h.name))
+			fmt.Fprintf(w, "  disable_checks = true\n")
+			fmt.Fprintf(w, "  disable_notifications = true\n")
+			fmt.Fprintf(w, "}\n\n")
+		}
+	}
+	return w.Flush()
+}
+
+// deviceMonitor returns a renderer for the devices as monitoring
+// config for kind, icinga or nagios, or nil for anything else.
+func deviceMonitor(kind string) deviceRender {
+	var write func(io.Writer, []*Device) error
+	switch strings.ToLower(kind) {
+	case "icinga", "icinga2":
+		write = writeIcinga
+	case "nagios":
+		write = writeNagios
+	default:
+		return nil
+	}
+	return func(out io.Writer, devices []*Device, _ []string) error {
+		return write(out, devices)
+	}
+}
@@ -0,0 +1,115 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func testMonitorDevices() []*Device {
+	return []*Device{
+		{Mac: "f0:9f:c2:00:00:03", Name: "gw", Type: "UDM", Site: "Office (default)", IP: "10.0.0.1"},
+		{Mac: "f0:9f:c2:00:00:01", Name: "core sw", Type: "USW", Site: "Office (default)", IP: "10.0.0.2", Model: "US24P250",
+			Uplink: &DevicePort{Mac: "f0:9f:c2:00:00:03", Port: "9"}},
+		{Mac: "f0:9f:c2:00:00:04", Name: "pending", Type: "USW", Site: "Warehouse (ab12cd34)",
+			Uplink: &DevicePort{Mac: "f0:9f:c2:00:00:01", Port: "3"}},
+		{Mac: "f0:9f:c2:00:00:02", Type: "UAP", Site: "Warehouse (ab12cd34)", IP: "10.1.0.5",
+			Uplink: &DevicePort{Mac: "f0:9f:c2:00:00:04", Port: "1"}},
+	}
+}
+
+func Test_writeNagios(t *testing.T) {
+	var buf bytes.Buffer
+	if err := deviceMonitor("nagios")(&buf, testMonitorDevices(), nil); err != nil {
+		t.Fatal(err)
+	}
+	want := `define hostgroup {
+    hostgroup_name  office_default
+    alias           Office (default)
+}
+
+define hostgroup {
+    hostgroup_name  warehouse_ab12cd34
+    alias           Warehouse (ab12cd34)
+}
+
+define host {
+    use             generic-host
+    host_name       gw
+    alias           gw
+    address         10.0.0.1
+    hostgroups      office_default
+    notes           UDM f0:9f:c2:00:00:03
+}
+
+define host {
+    use             generic-host
+    host_name       core-sw
+    alias           core sw
+    address         10.0.0.2
+    parents         gw
+    hostgroups      office_default
+    notes           USW US24P250 f0:9f:c2:00:00:01
+}
+
+define host {
+    use             generic-host
+    host_name       f0-9f-c2-00-00-02
+    alias           f0:9f:c2:00:00:02
+    address         10.1.0.5
+    parents         core-sw
+    hostgroups      warehouse_ab12cd34
+    notes           UAP f0:9f:c2:00:00:02
+}
+
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func Test_writeIcinga(t *testing.T) {
+	var buf bytes.Buffer
+	if err := deviceMonitor("Icinga")(&buf, testMonitorDevices()[:2], nil); err != nil {
+		t.Fatal(err)
+	}
+	want := `object HostGroup "office_default" {
+  display_name = "Office (default)"
+}
+
+object Host "gw" {
+  import "generic-host"
+  address = "10.0.0.1"
+  display_name = "gw"
+  groups = [ "office_default" ]
+  vars.mac = "f0:9f:c2:00:00:03"
+  vars.type = "UDM"
+}
+
+object Host "core-sw" {
+  import "generic-host"
+  address = "10.0.0.2"
+  display_name = "core sw"
+  groups = [ "office_default" ]
+  vars.mac = "f0:9f:c2:00:00:01"
+  vars.type = "USW"
+  vars.model = "US24P250"
+}
+
+object Dependency "uplink" {
+  parent_host_name = "gw"
+  child_host_name = "core-sw"
+  disable_checks = true
+  disable_notifications = true
+}
+
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if deviceMonitor("zabbix") != nil {
+		t.Error("expected no renderer for zabbix")
+	}
+}
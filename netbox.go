@@ -0,0 +1,279 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/unpoller/unifi"
+)
+
+var (
+	netboxCmd      = flag.NewFlagSet("netbox", flag.ExitOnError)
+	netboxDirFlag  = netboxCmd.String("dir", "netbox", "directory to write the csv files to")
+	netboxSiteFlag = netboxCmd.String("site", "", "comma separated list of sites to include")
+)
+
+// netboxManufacturer is the manufacturer of every device.
+const netboxManufacturer = "Ubiquiti"
+
+// netboxRoleColor is the color of the device roles, NetBox grey.
+const netboxRoleColor = "9e9e9e"
+
+// netboxRoles maps device types to NetBox device roles.
+var netboxRoles = map[string]string{
+	"USW": "Switch",
+	"UAP": "Access Point",
+	"USG": "Gateway",
+	"UXG": "Gateway",
+	"UDM": "Gateway",
+}
+
+// netboxInterfaceTypes maps port speeds in Mbps to NetBox interface
+// types. The speed is only known for ports that are up.
+var netboxInterfaceTypes = map[int]string{
+	10:    "10base-t",
+	100:   "100base-tx",
+	1000:  "1000base-t",
+	2500:  "2.5gbase-t",
+	5000:  "5gbase-t",
+	10000: "10gbase-x-sfpp",
+}
+
+// netboxTable is one csv file in the NetBox bulk import format.
+type netboxTable struct {
+	name   string
+	fields []string
+	rows   []map[string]string
+}
+
+// add appends a row with values in the order of fields.
+func (t *netboxTable) add(values ...string) {
+	row := make(map[string]string, len(t.fields))
+	for i, field := range t.fields {
+		row[field] = values[i]
+	}
+	t.rows = append(t.rows, row)
+}
+
+func (t *netboxTable) write(dir string) error {
+	f, err := os.Create(filepath.Join(dir, t.name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := writeCsv(f, t.fields, len(t.rows), func(row int, field string) string {
+		return t.rows[row][field]
+	}); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func netboxRun(uni *unifi.Unifi, sites []*unifi.Site, args []string) {
+	check(netboxCmd.Parse(args))
+	sites = filterSites(sites, splitList(*netboxSiteFlag))
+
+	snap, err := takeSnapshot(uni, sites)
+	if err != nil {
+		log.Fatalln("Error:", err)
+	}
+	networks, err := uni.GetNetworks(sites)
+	if err != nil {
+		log.Fatalln("Error:", err)
+	}
+	tables := buildNetbox(snap, buildNetworks(sites, networks))
+	check(os.MkdirAll(*netboxDirFlag, 0755))
+	for _, t := range tables {
+		check(t.write(*netboxDirFlag))
+		fmt.Printf("wrote %d rows to %s\n", len(t.rows), filepath.Join(*netboxDirFlag, t.name))
+	}
+}
+
+// buildNetbox returns the tables to import in order: the manufacturer,
+// device roles and device types the devices need, sites, devices,
+// interfaces, cables, and the IP and MAC addresses of the clients.
+func buildNetbox(snap *snapshot, networks []*NetworkInfo) []*netboxTable {
+	manufacturers := &netboxTable{name: "manufacturers.csv", fields: []string{"name", "slug"}}
+	roles := &netboxTable{name: "device-roles.csv", fields: []string{"name", "slug", "color"}}
+	types := &netboxTable{name: "device-types.csv", fields: []string{"manufacturer", "model", "slug"}}
+	sites := &netboxTable{name: "sites.csv", fields: []string{"name", "slug", "status", "description"}}
+	devices := &netboxTable{name: "devices.csv", fields: []string{"name", "role", "manufacturer", "device_type", "site", "status", "description"}}
+	interfaces := &netboxTable{name: "interfaces.csv", fields: []string{"device", "name", "type", "enabled", "description"}}
+	cables := &netboxTable{name: "cables.csv", fields: []string{"side_a_device", "side_a_type", "side_a_name", "side_b_device", "side_b_type", "side_b_name", "status"}}
+	addresses := &netboxTable{name: "ip-addresses.csv", fields: []string{"address", "status", "dns_name", "description"}}
+	macs := &netboxTable{name: "mac-addresses.csv", fields: []string{"mac_address", "device", "interface", "is_primary", "description"}}
+	manufacturers.add(netboxManufacturer, netboxSlug(netboxManufacturer))
+
+	sitenames := make(map[string]string)
+	for _, s := range snap.Sites {
+		name := s.Desc
+		if name == "" {
+			name = s.Name
+		}
+		sitenames[s.SiteName] = name
+		sites.add(name, netboxSlug(name), "active", "UniFi site "+s.Name)
+	}
+
+	// device names must be unique, a name that is used gets the MAC
+	names := make(map[string]string)
+	used := make(map[string]bool)
+	added := make(map[string]bool)
+	for _, d := range snap.Devices {
+		name := d.Displayname()
+		if used[name] {
+			name += " " + d.Mac
+		}
+		used[name] = true
+		names[d.Mac] = name
+
+		role := netboxRoles[d.Type]
+		if role == "" {
+			role = d.Type
+		}
+		model := d.Model
+		if model == "" {
+			model = d.Type
+		}
+		if !added["role "+role] {
+			added["role "+role] = true
+			roles.add(role, netboxSlug(role), netboxRoleColor)
+		}
+		if !added["type "+model] {
+			added["type "+model] = true
+			types.add(netboxManufacturer, model, netboxSlug(model))
+		}
+		status := "active"
+		if d.State != "online" {
+			status = "offline"
+		}
+		site := sitenames[d.Site]
+		if site == "" {
+			site = d.Site
+		}
+		devices.add(name, role, netboxManufacturer, model, site, status, d.Mac)
+	}
+
+	type port struct {
+		mac  string
+		name string
+	}
+	known := make(map[port]bool)
+	uplinks := make(map[string]string)
+	for _, p := range snap.Ports {
+		device, ok := names[p.SwitchMac]
+		if !ok {
+			continue
+		}
+		name := "Port " + strconv.Itoa(p.Port)
+		kind := netboxInterfaceTypes[p.Speed]
+		if kind == "" {
+			kind = "other"
+		}
+		desc := p.Name
+		if desc == name {
+			desc = ""
+		}
+		known[port{p.SwitchMac, name}] = true
+		interfaces.add(device, name, kind, strconv.FormatBool(p.Enabled), desc)
+		if p.Uplink && uplinks[p.SwitchMac] == "" {
+			uplinks[p.SwitchMac] = name
+		}
+	}
+	// cables and MACs need an interface, devices without a port
+	// table get one for what they are connected with
+	ensure := func(mac, name, kind string) {
+		if !known[port{mac, name}] {
+			known[port{mac, name}] = true
+			interfaces.add(names[mac], name, kind, "true", "")
+		}
+	}
+
+	cabled := make(map[port]bool)
+	for _, d := range snap.Devices {
+		if d.Uplink == nil || d.Uplink.Port == "" {
+			continue
+		}
+		if _, ok := names[d.Uplink.Mac]; !ok || d.Uplink.Mac == d.Mac {
+			continue
+		}
+		a := port{d.Uplink.Mac, "Port " + d.Uplink.Port}
+		b := port{d.Mac, uplinks[d.Mac]}
+		if b.name == "" {
+			b.name = "eth0"
+		}
+		if cabled[a] || cabled[b] {
+			continue
+		}
+		cabled[a], cabled[b] = true, true
+		ensure(a.mac, a.name, "other")
+		ensure(b.mac, b.name, "other")
+		cables.add(names[a.mac], "dcim.interface", a.name, names[b.mac], "dcim.interface", b.name, "connected")
+	}
+
+	for _, c := range snap.Clients {
+		desc := strings.TrimSpace(c.Name + " " + c.Mac)
+		if c.IP != "" {
+			status := "dhcp"
+			if c.UseFixedIP.Val {
+				status = "active"
+			}
+			addresses.add(clientPrefix(c, networks), status, c.Hostname, desc)
+		}
+		// the MAC is assigned to the switch port or access point the
+		// client is connected to, it is not the primary MAC of that
+		var device, iface, primary string
+		if _, ok := names[c.SwMac]; ok && c.SwPort.Val > 0 {
+			device, iface = names[c.SwMac], "Port "+strconv.Itoa(int(c.SwPort.Val))
+			ensure(c.SwMac, iface, "other")
+		} else if _, ok := names[c.ApMac]; ok {
+			device, iface = names[c.ApMac], "wlan0"
+			ensure(c.ApMac, iface, "other-wireless")
+		}
+		if device != "" {
+			primary = "false"
+		}
+		macs.add(c.Mac, device, iface, primary, strings.TrimSpace(c.Name+" "+c.IP))
+	}
+	return []*netboxTable{manufacturers, roles, types, sites, devices, interfaces, cables, addresses, macs}
+}
+
+// clientPrefix returns the address of c with the prefix length of
+// the network it is in, or as a single address if that is unknown.
+func clientPrefix(c *unifi.Client, networks []*NetworkInfo) string {
+	ip, err := netip.ParseAddr(c.IP)
+	if err != nil {
+		return c.IP
+	}
+	bits := ip.BitLen()
+	for _, n := range networks {
+		if n.Site != c.SiteName {
+			continue
+		}
+		subnet, err := netip.ParsePrefix(n.Subnet)
+		if err == nil && subnet.Masked().Contains(ip) {
+			bits = subnet.Bits()
+			if n.Name == c.Network {
+				break
+			}
+		}
+	}
+	return netip.PrefixFrom(ip, bits).String()
+}
+
+// netboxSlug is name in lower case with anything but
+// letters and digits replaced by single dashes.
+func netboxSlug(name string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	}), "-")
+}
@@ -0,0 +1,118 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/unpoller/unifi"
+)
+
+func Test_buildNetbox(t *testing.T) {
+	snap := testPoller().snap
+	snap.Devices = []*Device{
+		{Mac: "f0:9f:c2:00:00:01", Name: "core", Type: "USW", Site: "Office (default)", State: "online", Model: "US24P250",
+			Uplink: &DevicePort{Mac: "f0:9f:c2:00:00:03", Port: "9"}},
+		{Mac: "f0:9f:c2:00:00:02", Name: "core", Type: "UAP", Site: "Office (default)", State: "offline",
+			Uplink: &DevicePort{Mac: "f0:9f:c2:00:00:01", Port: "3"}},
+		{Mac: "f0:9f:c2:00:00:03", Name: "gw", Type: "UDM", Site: "Office (default)", State: "online"},
+	}
+	snap.Ports = []*SwitchPort{
+		{SwitchMac: "f0:9f:c2:00:00:01", Port: 1, Name: "Port 1", Enabled: true, Uplink: true, Speed: 10000},
+		{SwitchMac: "f0:9f:c2:00:00:01", Port: 3, Name: "AP", Enabled: true, Speed: 1000},
+	}
+	snap.Clients[0].UseFixedIP = unifi.FlexBool{Val: true}
+	snap.Clients[0].Network = "LAN"
+	snap.Clients[1].IP = ""
+	snap.Clients[0].SwMac = "f0:9f:c2:00:00:01"
+	snap.Clients[0].SwPort = unifi.FlexInt{Val: 3}
+	snap.Clients[1].ApMac = "f0:9f:c2:00:00:02"
+	snap.Clients = append(snap.Clients, &unifi.Client{Mac: "00:00:00:00:00:09", SiteName: "Office (default)"})
+	networks := []*NetworkInfo{{Site: "Office (default)", Name: "LAN", Subnet: "10.0.0.1/24"}}
+
+	want := map[string]string{
+		"manufacturers.csv": `name,slug
+Ubiquiti,ubiquiti
+`,
+		"device-roles.csv": `name,slug,color
+Switch,switch,9e9e9e
+Access Point,access-point,9e9e9e
+Gateway,gateway,9e9e9e
+`,
+		"device-types.csv": `manufacturer,model,slug
+Ubiquiti,US24P250,us24p250
+Ubiquiti,UAP,uap
+Ubiquiti,UDM,udm
+`,
+		"sites.csv": `name,slug,status,description
+Office,office,active,UniFi site default
+Warehouse,warehouse,active,UniFi site ab12cd34
+`,
+		"devices.csv": `name,role,manufacturer,device_type,site,status,description
+core,Switch,Ubiquiti,US24P250,Office,active,f0:9f:c2:00:00:01
+core f0:9f:c2:00:00:02,Access Point,Ubiquiti,UAP,Office,offline,f0:9f:c2:00:00:02
+gw,Gateway,Ubiquiti,UDM,Office,active,f0:9f:c2:00:00:03
+`,
+		"interfaces.csv": `device,name,type,enabled,description
+core,Port 1,10gbase-x-sfpp,true,
+core,Port 3,1000base-t,true,AP
+gw,Port 9,other,true,
+core f0:9f:c2:00:00:02,eth0,other,true,
+core f0:9f:c2:00:00:02,wlan0,other-wireless,true,
+`,
+		"cables.csv": `side_a_device,side_a_type,side_a_name,side_b_device,side_b_type,side_b_name,status
+gw,dcim.interface,Port 9,core,dcim.interface,Port 1,connected
+core,dcim.interface,Port 3,core f0:9f:c2:00:00:02,dcim.interface,eth0,connected
+`,
+		"ip-addresses.csv": `address,status,dns_name,description
+10.0.0.2/24,active,,00:11:22:33:44:55
+`,
+		"mac-addresses.csv": `mac_address,device,interface,is_primary,description
+00:11:22:33:44:55,core,Port 3,false,10.0.0.2
+66:77:88:99:aa:bb,core f0:9f:c2:00:00:02,wlan0,false,
+00:00:00:00:00:09,,,,
+`,
+	}
+	dir := t.TempDir()
+	tables := buildNetbox(snap, networks)
+	if len(tables) != len(want) {
+		t.Errorf("got %d tables, want %d", len(tables), len(want))
+	}
+	for _, table := range tables {
+		if err := table.write(dir); err != nil {
+			t.Fatal(err)
+		}
+		got, err := os.ReadFile(filepath.Join(dir, table.name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, []byte(want[table.name])) {
+			t.Errorf("%s got:\n%s\nwant:\n%s", table.name, got, want[table.name])
+		}
+	}
+}
+
+func Test_clientPrefix(t *testing.T) {
+	networks := []*NetworkInfo{
+		{Site: "a", Name: "LAN", Subnet: "10.0.0.1/16"},
+		{Site: "a", Name: "IoT", Subnet: "10.0.5.1/24"},
+	}
+	tests := []struct {
+		client *unifi.Client
+		want   string
+	}{
+		{&unifi.Client{IP: "10.0.5.9", SiteName: "a", Network: "IoT"}, "10.0.5.9/24"},
+		{&unifi.Client{IP: "10.0.1.9", SiteName: "a"}, "10.0.1.9/16"},
+		{&unifi.Client{IP: "10.0.1.9", SiteName: "b"}, "10.0.1.9/32"},
+		{&unifi.Client{IP: "fe80::1", SiteName: "a"}, "fe80::1/128"},
+	}
+	for _, tt := range tests {
+		if got := clientPrefix(tt.client, networks); got != tt.want {
+			t.Errorf("clientPrefix(%s) = %s, want %s", tt.client.IP, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/unpoller/unifi"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	NETWORK_SITE      = "Site"
+	NETWORK_NAME      = "Name"
+	NETWORK_PURPOSE   = "Purpose"
+	NETWORK_VLAN      = "VLAN"
+	NETWORK_SUBNET    = "Subnet"
+	NETWORK_DHCPSTART = "DHCP Start"
+	NETWORK_DHCPSTOP  = "DHCP Stop"
+	NETWORK_DOMAIN    = "Domain"
+	NETWORK_CLIENTS   = "Clients"
+)
+
+var (
+	networksCmd       = flag.NewFlagSet("networks", flag.ExitOnError)
+	networkOutputFlag = networksCmd.String("output", "", "filename to output to. [*.xlsx, *.json, *.ndjson, *.yaml, *.toml, *.csv, *.md, *.html]")
+	networkSiteFlag   = networksCmd.String("site", "", "comma separated list of sites to include")
+	networkFieldsFlag = networksCmd.String("fields", "", "comma separated list of fields to output")
+	network_fields    = []string{
+		NETWORK_SITE, NETWORK_NAME, NETWORK_PURPOSE, NETWORK_VLAN,
+		NETWORK_SUBNET, NETWORK_DHCPSTART, NETWORK_DHCPSTOP, NETWORK_DOMAIN,
+		NETWORK_CLIENTS,
+	}
+)
+
+// NetworkInfo is a network configured on a site.
+type NetworkInfo struct {
+	Site      string
+	Name      string
+	Purpose   string
+	VLAN      int
+	Subnet    string
+	DHCPStart string
+	DHCPStop  string
+	Domain    string
+	Clients   int
+}
+
+// networkRender outputs networks using the selected fields.
+// A nil list of fields means the default ones.
+type networkRender func(io.Writer, []*NetworkInfo, []string) error
+
+func generateNetworks(uni *unifi.Unifi, sites []*unifi.Site) {
+	fields, err := parseFields(*networkFieldsFlag, network_fields)
+	check(err)
+
+	sites = filterSites(sites, splitList(*networkSiteFlag))
+	unifinetworks, err := uni.GetNetworks(sites)
+	if err != nil {
+		log.Fatalln("Error:", err)
+	}
+	clients, err := uni.GetClients(sites)
+	if err != nil {
+		log.Fatalln("Error:", err)
+	}
+	networks := buildNetworks(sites, unifinetworks)
+	countNetworkClients(networks, clients)
+
+	ext := ".table"
+	if *networkOutputFlag != "" {
+		ext = filepath.Ext(*networkOutputFlag)
+	}
+	renderer := getNetworkRender(ext)
+	if renderer == nil {
+		log.Fatalf("unsupported extension for %s", *networkOutputFlag)
+	}
+	if *networkOutputFlag != "" {
+		f := mustCreateFile(*networkOutputFlag)
+		defer f.Close()
+		err = renderer(f, networks, fields)
+	} else {
+		err = renderer(os.Stdout, networks, fields)
+	}
+	if err != nil {
+		log.Fatalf("error writing '%s': %v", *networkOutputFlag, err)
+	}
+}
+
+// getNetworkRender returns the renderer for a file extension
+// or nil if the extension is not supported.
+func getNetworkRender(ext string) networkRender {
+	switch ext {
+	case ".xlsx":
+		return networkExcel
+	case ".json":
+		return networkJSON
+	case ".ndjson":
+		return networkNDJSON
+	case ".yaml", ".yml":
+		return networkYAML
+	case ".toml":
+		return networkTOML
+	case ".csv":
+		return networkCsv
+	case ".md":
+		return networkMarkdown
+	case ".html":
+		return networkHTML
+	case ".table":
+		return networkTable
+	}
+	return nil
+}
+
+// buildNetworks takes the network configs and resolves their sites.
+func buildNetworks(sites []*unifi.Site, networks []unifi.Network) []*NetworkInfo {
+	sitenames := make(map[string]string)
+	for _, s := range sites {
+		sitenames[s.ID] = s.SiteName
+	}
+	result := make([]*NetworkInfo, len(networks))
+	for i, n := range networks {
+		result[i] = &NetworkInfo{
+			Site:      sitenames[n.SiteID],
+			Name:      n.Name,
+			Purpose:   n.Purpose,
+			VLAN:      int(n.Vlan.Val),
+			Subnet:    n.IPSubnet,
+			DHCPStart: n.DhcpdStart,
+			DHCPStop:  n.DhcpdStop,
+			Domain:    n.DomainName,
+		}
+	}
+	return result
+}
+
+// countNetworkClients sets the number of active clients on each
+// network from the network name the controller gives every client.
+func countNetworkClients(networks []*NetworkInfo, clients []*unifi.Client) {
+	type key struct{ site, name string }
+	counts := make(map[key]int)
+	for _, c := range clients {
+		counts[key{c.SiteName, c.Network}]++
+	}
+	for _, n := range networks {
+		n.Clients = counts[key{n.Site, n.Name}]
+	}
+}
+
+func getNetworkValue(n *NetworkInfo, name string) string {
+	switch name {
+	case NETWORK_SITE:
+		return n.Site
+	case NETWORK_NAME:
+		return n.Name
+	case NETWORK_PURPOSE:
+		return n.Purpose
+	case NETWORK_VLAN:
+		if n.VLAN == 0 {
+			return ""
+		}
+		return strconv.Itoa(n.VLAN)
+	case NETWORK_SUBNET:
+		return n.Subnet
+	case NETWORK_DHCPSTART:
+		return n.DHCPStart
+	case NETWORK_DHCPSTOP:
+		return n.DHCPStop
+	case NETWORK_DOMAIN:
+		return n.Domain
+	case NETWORK_CLIENTS:
+		return strconv.Itoa(n.Clients)
+	default:
+		return "#UNSUPPORTED"
+	}
+}
+
+// networkValues returns a rowValue for a list of networks
+func networkValues(networks []*NetworkInfo) rowValue {
+	return func(row int, field string) string {
+		return getNetworkValue(networks[row], field)
+	}
+}
+
+// networkCells returns a rowCell for a list of networks with VLAN
+// and clients as numbers
+func networkCells(networks []*NetworkInfo) rowCell {
+	return func(row int, field string) any {
+		if field == NETWORK_VLAN && networks[row].VLAN > 0 {
+			return networks[row].VLAN
+		}
+		if field == NETWORK_CLIENTS {
+			return networks[row].Clients
+		}
+		return getNetworkValue(networks[row], field)
+	}
+}
+
+func networkTable(out io.Writer, networks []*NetworkInfo, fields []string) error {
+	if fields == nil {
+		fields = network_fields
+	}
+	return writeTable(out, fields, len(networks), networkValues(networks))
+}
+
+func networkJSON(out io.Writer, networks []*NetworkInfo, fields []string) error {
+	if fields == nil {
+		fields = network_fields
+	}
+	return writeJSON(out, fields, len(networks), networkCells(networks))
+}
+
+func networkNDJSON(out io.Writer, networks []*NetworkInfo, fields []string) error {
+	if fields == nil {
+		fields = network_fields
+	}
+	return writeNDJSON(out, fields, len(networks), networkCells(networks))
+}
+
+func networkYAML(out io.Writer, networks []*NetworkInfo, fields []string) error {
+	if fields == nil {
+		fields = network_fields
+	}
+	return writeYAML(out, fields, len(networks), networkCells(networks), nil)
+}
+
+func networkTOML(out io.Writer, networks []*NetworkInfo, fields []string) error {
+	if fields == nil {
+		fields = network_fields
+	}
+	return writeTOML(out, "networks", fields, len(networks), networkCells(networks), nil)
+}
+
+func networkCsv(out io.Writer, networks []*NetworkInfo, fields []string) error {
+	if fields == nil {
+		fields = network_fields
+	}
+	return writeCsv(out, fields, len(networks), networkValues(networks))
+}
+
+func networkExcel(out io.Writer, networks []*NetworkInfo, fields []string) error {
+	if fields == nil {
+		fields = network_fields
+	}
+	f := excelize.NewFile()
+	if err := writeSheet(f, f.GetSheetName(0), fields, len(networks), networkCells(networks), sheetStyle{}); err != nil {
+		return err
+	}
+	return f.Write(out)
+}
+
+func networkMarkdown(out io.Writer, networks []*NetworkInfo, fields []string) error {
+	if fields == nil {
+		fields = network_fields
+	}
+	return writeMarkdown(out, fields, len(networks), networkValues(networks))
+}
+
+func networkHTML(out io.Writer, networks []*NetworkInfo, fields []string) error {
+	if fields == nil {
+		fields = network_fields
+	}
+	return writeHTML(out, "Networks", fields, len(networks), networkValues(networks))
+}
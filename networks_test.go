@@ -0,0 +1,52 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/unpoller/unifi"
+)
+
+func Test_countNetworkClients(t *testing.T) {
+	sites := []*unifi.Site{
+		{ID: "s1", SiteName: "Office (default)"},
+		{ID: "s2", SiteName: "Warehouse (ab12cd34)"},
+	}
+	networks := buildNetworks(sites, []unifi.Network{
+		{SiteID: "s1", Name: "LAN", Purpose: "corporate", IPSubnet: "10.0.0.1/24", DhcpdStart: "10.0.0.100", DhcpdStop: "10.0.0.200"},
+		{SiteID: "s1", Name: "IoT", Purpose: "corporate", Vlan: unifi.FlexInt{Val: 40}, IPSubnet: "10.0.40.1/24"},
+		{SiteID: "s2", Name: "LAN", Purpose: "corporate", IPSubnet: "10.1.0.1/24"},
+	})
+	clients := []*unifi.Client{
+		{Mac: "00:00:00:00:00:01", SiteName: "Office (default)", Network: "LAN"},
+		{Mac: "00:00:00:00:00:02", SiteName: "Office (default)", Network: "LAN"},
+		{Mac: "00:00:00:00:00:03", SiteName: "Office (default)", Network: "IoT"},
+		{Mac: "00:00:00:00:00:04", SiteName: "Warehouse (ab12cd34)", Network: "Guest"},
+	}
+	countNetworkClients(networks, clients)
+
+	var buf bytes.Buffer
+	fields := []string{NETWORK_SITE, NETWORK_NAME, NETWORK_VLAN, NETWORK_SUBNET, NETWORK_DHCPSTART, NETWORK_CLIENTS}
+	if err := networkCsv(&buf, networks, fields); err != nil {
+		t.Fatal(err)
+	}
+	want := `Site,Name,VLAN,Subnet,DHCP Start,Clients
+Office (default),LAN,,10.0.0.1/24,10.0.0.100,2
+Office (default),IoT,40,10.0.40.1/24,,1
+Warehouse (ab12cd34),LAN,,10.1.0.1/24,,0
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	buf.Reset()
+	if err := networkNDJSON(&buf, networks[1:2], []string{NETWORK_NAME, NETWORK_VLAN, NETWORK_CLIENTS}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\"Name\":\"IoT\",\"VLAN\":40,\"Clients\":1}\n"; buf.String() != want {
+		t.Errorf("got %s, want %s", buf.String(), want)
+	}
+}
@@ -0,0 +1,24 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+
+//go:build !sqlite
+
+package main
+
+import (
+	"errors"
+	"log"
+)
+
+// errNoSQLite is returned when unimac is built without the sqlite tag.
+// The SQLite driver needs cgo so it is left out of the static binary.
+var errNoSQLite = errors.New("unimac is built without SQLite support, build with -tags sqlite")
+
+func writeSQLite(filename string, snap *snapshot, networks []*NetworkInfo) (int64, error) {
+	return 0, errNoSQLite
+}
+
+func queryRun(args []string) {
+	log.Fatalln("Error:", errNoSQLite)
+}
@@ -0,0 +1,175 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/unpoller/unifi"
+)
+
+var (
+	notifyWebhookFlag = watchCmd.String("webhook", "", "URL to POST a JSON payload to when an unknown MAC joins")
+	notifyExecFlag    = watchCmd.String("exec", "", "command to run when an unknown MAC joins, with the event in UNIMAC_* environment variables")
+	notifyKnownFlag   = watchCmd.String("known", "", "file with known MACs, one per line, that never notify")
+	notifyHoursFlag   = watchCmd.Int("hours", 24*30, "clients the controller has seen within this many hours are known and never notify")
+	notifyQuietFlag   = watchCmd.Duration("quiet", time.Hour, "do not notify about the same MAC again within this period")
+)
+
+// notifier passes join events of unknown MACs on to its targets,
+// at most once per quiet period for each MAC.
+type notifier struct {
+	known   map[string]bool
+	quiet   time.Duration
+	last    map[string]time.Time
+	targets []eventHandler
+}
+
+func newNotifier(known map[string]bool, quiet time.Duration, targets ...eventHandler) *notifier {
+	return &notifier{
+		known:   known,
+		quiet:   quiet,
+		last:    make(map[string]time.Time),
+		targets: targets,
+	}
+}
+
+func (n *notifier) handle(ev Event) error {
+	mac := normalizeMac(ev.Mac)
+	if ev.Type != EVENT_JOIN || n.known[mac] {
+		return nil
+	}
+	// forget MACs whose quiet period is over so that a long
+	// running watch does not keep every MAC it has seen
+	for m, t := range n.last {
+		if ev.Time.Sub(t) >= n.quiet {
+			delete(n.last, m)
+		}
+	}
+	if _, ok := n.last[mac]; ok {
+		return nil
+	}
+	n.last[mac] = ev.Time
+
+	var failed []string
+	for _, target := range n.targets {
+		if err := target(ev); err != nil {
+			failed = append(failed, err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return errors.New(strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// readKnownMacs reads a file with one MAC per line.
+// Empty lines and lines starting with # are ignored.
+func readKnownMacs(filename string) (map[string]bool, error) {
+	known := make(map[string]bool)
+	if filename == "" {
+		return known, nil
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		known[normalizeMac(strings.Fields(line)[0])] = true
+	}
+	return known, scanner.Err()
+}
+
+// webhook returns a handler that POSTs the event as JSON to url.
+func webhook(url string) eventHandler {
+	client := &http.Client{Timeout: 10 * time.Second}
+	return func(ev Event) error {
+		body, err := json.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("webhook %s returned %s", url, resp.Status)
+		}
+		return nil
+	}
+}
+
+// eventEnv returns the event as UNIMAC_* environment variables.
+func eventEnv(ev Event) []string {
+	return []string{
+		"UNIMAC_EVENT=" + ev.Type,
+		"UNIMAC_TIME=" + ev.Time.Format(time.RFC3339),
+		"UNIMAC_MAC=" + ev.Mac,
+		"UNIMAC_SITE=" + ev.Site,
+		"UNIMAC_HOSTNAME=" + ev.Hostname,
+		"UNIMAC_NAME=" + ev.Name,
+		"UNIMAC_IP=" + ev.IP,
+		"UNIMAC_FROM=" + ev.From,
+		"UNIMAC_TO=" + ev.To,
+	}
+}
+
+// execCommand returns a handler that runs command through the
+// shell with the event in the environment.
+func execCommand(command string) eventHandler {
+	return func(ev Event) error {
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.Command("cmd", "/C", command)
+		} else {
+			cmd = exec.Command("sh", "-c", command)
+		}
+		cmd.Env = append(os.Environ(), eventEnv(ev)...)
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		return cmd.Run()
+	}
+}
+
+// notifyHandlers returns the handlers configured by the watch flags.
+// MACs in the known file and clients the controller has already seen
+// are known.
+func notifyHandlers(uni *unifi.Unifi, sites []*unifi.Site) []eventHandler {
+	var targets []eventHandler
+	if *notifyWebhookFlag != "" {
+		targets = append(targets, webhook(*notifyWebhookFlag))
+	}
+	if *notifyExecFlag != "" {
+		targets = append(targets, execCommand(*notifyExecFlag))
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+	known, err := readKnownMacs(*notifyKnownFlag)
+	check(err)
+	users, err := uni.GetUsers(sites, *notifyHoursFlag)
+	check(err)
+	for _, u := range users {
+		known[normalizeMac(u.Mac)] = true
+	}
+	return []eventHandler{newNotifier(known, *notifyQuietFlag, targets...).handle}
+}
@@ -0,0 +1,86 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_notifierWebhook(t *testing.T) {
+	var got []Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ev Event
+		if err := json.NewDecoder(r.Body).Decode(&ev); err != nil {
+			t.Errorf("decoding payload: %v", err)
+		}
+		got = append(got, ev)
+	}))
+	defer srv.Close()
+
+	known := map[string]bool{"00:11:22:33:44:55": true}
+	n := newNotifier(known, time.Hour, webhook(srv.URL))
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	events := []Event{
+		{Time: start, Type: EVENT_JOIN, Mac: "aa:bb:cc:dd:ee:ff", IP: "10.0.0.7"},
+		{Time: start.Add(time.Minute), Type: EVENT_LEAVE, Mac: "aa:bb:cc:dd:ee:ff"},
+		{Time: start.Add(2 * time.Minute), Type: EVENT_JOIN, Mac: "aa:bb:cc:dd:ee:ff"},
+		{Time: start.Add(2 * time.Minute), Type: EVENT_JOIN, Mac: "00:11:22:33:44:55"},
+		{Time: start.Add(2 * time.Hour), Type: EVENT_JOIN, Mac: "AA-BB-CC-DD-EE-FF"},
+	}
+	for _, ev := range events {
+		if err := n.handle(ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d notifications %v, want 2", len(got), got)
+	}
+	if got[0].Mac != "aa:bb:cc:dd:ee:ff" || got[0].IP != "10.0.0.7" {
+		t.Errorf("first payload = %+v", got[0])
+	}
+}
+
+func Test_notifierExec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	out := filepath.Join(t.TempDir(), "out.txt")
+	n := newNotifier(nil, time.Hour, execCommand(`echo "$UNIMAC_EVENT $UNIMAC_MAC" > `+out))
+	if err := n.handle(Event{Time: time.Now(), Type: EVENT_JOIN, Mac: "aa:bb:cc:dd:ee:ff"}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "join aa:bb:cc:dd:ee:ff" {
+		t.Errorf("command wrote %q", got)
+	}
+}
+
+func Test_notifierPrune(t *testing.T) {
+	count := 0
+	n := newNotifier(nil, time.Hour, func(Event) error { count++; return nil })
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, mac := range []string{"aa:bb:cc:dd:ee:01", "aa:bb:cc:dd:ee:02", "aa:bb:cc:dd:ee:03"} {
+		ev := Event{Time: start.Add(time.Duration(i) * time.Hour), Type: EVENT_JOIN, Mac: mac}
+		if err := n.handle(ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if count != 3 {
+		t.Errorf("notified %d times, want 3", count)
+	}
+	if len(n.last) != 1 {
+		t.Errorf("remembers %d MACs, want only the one within the quiet period", len(n.last))
+	}
+}
@@ -0,0 +1,309 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/unpoller/unifi"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	PORT_SITE    = "Site"
+	PORT_SWITCH  = "Switch"
+	PORT_INDEX   = "Port"
+	PORT_NAME    = "Name"
+	PORT_UP      = "Up"
+	PORT_SPEED   = "Speed"
+	PORT_POE     = "PoE"
+	PORT_UPLINK  = "Uplink"
+	PORT_CLIENTS = "Clients"
+)
+
+var (
+	portsCmd         = flag.NewFlagSet("ports", flag.ExitOnError)
+	portOutputFlag   = portsCmd.String("output", "", "filename to output to. [*.xlsx, *.json, *.ndjson, *.yaml, *.toml, *.csv, *.md, *.html]")
+	portSiteFlag     = portsCmd.String("site", "", "comma separated list of sites to include")
+	portFieldsFlag   = portsCmd.String("fields", "", "comma separated list of fields to output")
+	portTemplateFlag = portsCmd.String("template", "", "xlsx workbook to fill or text/template file to render instead of the output format")
+	portAnchorFlag   = portsCmd.String("anchor", "", "defined name or cell in the template where the header is written")
+	port_fields      = []string{
+		PORT_SITE, PORT_SWITCH, PORT_INDEX, PORT_NAME, PORT_UP,
+		PORT_SPEED, PORT_POE, PORT_UPLINK, PORT_CLIENTS,
+	}
+	portStyle = sheetStyle{offline: map[string]string{PORT_UP: "FALSE"}}
+)
+
+// SwitchPort is a single port on a switch together
+// with the MACs of the clients connected to it.
+type SwitchPort struct {
+	Site      string
+	SwitchMac string
+	Switch    string
+	Port      int
+	Name      string
+	Up        bool
+	Enabled   bool
+	Speed     int
+	PoE       bool
+	Uplink    bool
+	Clients   []string
+}
+
+// portRender outputs switch ports using the selected fields.
+// A nil list of fields means the default ones.
+type portRender func(io.Writer, []*SwitchPort, []string) error
+
+func generatePorts(uni *unifi.Unifi, sites []*unifi.Site) {
+	fields, err := parseFields(*portFieldsFlag, port_fields)
+	check(err)
+
+	sites = filterSites(sites, splitList(*portSiteFlag))
+	devices, err := uni.GetDevices(sites)
+	if err != nil {
+		log.Fatalln("Error:", err)
+	}
+	clients, err := uni.GetClients(sites)
+	if err != nil {
+		log.Fatalln("Error:", err)
+	}
+	ports := buildPorts(devices, clients)
+	fmt.Printf("%d ports on %d switches\n", len(ports), len(devices.USWs))
+
+	ext := ".table"
+	if *portOutputFlag != "" {
+		ext = filepath.Ext(*portOutputFlag)
+	}
+	renderer := getPortRender(ext)
+	switch {
+	case filepath.Ext(*portTemplateFlag) == ".xlsx":
+		if ext != ".xlsx" {
+			log.Fatalln("Error: an xlsx template needs an xlsx output")
+		}
+		renderer = func(out io.Writer, ports []*SwitchPort, fields []string) error {
+			if fields == nil {
+				fields = port_fields
+			}
+			return writeTemplate(out, *portTemplateFlag, *portAnchorFlag, fields, len(ports), portCells(ports), portStyle)
+		}
+	case *portTemplateFlag != "":
+		renderer = func(out io.Writer, ports []*SwitchPort, fields []string) error {
+			if fields == nil {
+				fields = port_fields
+			}
+			return writeTextTemplate(out, *portTemplateFlag, fields, len(ports), portValues(ports))
+		}
+	}
+	if renderer == nil {
+		log.Fatalf("unsupported extension for %s", *portOutputFlag)
+	}
+	if *portOutputFlag != "" {
+		f := mustCreateFile(*portOutputFlag)
+		defer f.Close()
+		err = renderer(f, ports, fields)
+	} else {
+		err = renderer(os.Stdout, ports, fields)
+	}
+	if err != nil {
+		log.Fatalf("error writing '%s': %v", *portOutputFlag, err)
+	}
+}
+
+// getPortRender returns the renderer for a file extension
+// or nil if the extension is not supported.
+func getPortRender(ext string) portRender {
+	switch ext {
+	case ".xlsx":
+		return portExcel
+	case ".json":
+		return portJSON
+	case ".ndjson":
+		return portNDJSON
+	case ".yaml", ".yml":
+		return portYAML
+	case ".toml":
+		return portTOML
+	case ".csv":
+		return portCsv
+	case ".md":
+		return portMarkdown
+	case ".html":
+		return portHTML
+	case ".table":
+		return portTable
+	}
+	return nil
+}
+
+// buildPorts lists the port table of every switch and
+// attaches the wired clients seen on each port.
+func buildPorts(devices *unifi.Devices, clients []*unifi.Client) []*SwitchPort {
+	type key struct {
+		mac  string
+		port int
+	}
+	clientmap := make(map[key][]string)
+	for _, c := range clients {
+		if c.SwMac == "" {
+			continue
+		}
+		k := key{c.SwMac, int(c.SwPort.Val)}
+		clientmap[k] = append(clientmap[k], c.Mac)
+	}
+
+	var ports []*SwitchPort
+	for _, sw := range devices.USWs {
+		name := sw.Name
+		if name == "" {
+			name = sw.Mac
+		}
+		for _, p := range sw.PortTable {
+			idx := int(p.PortIdx.Val)
+			ports = append(ports, &SwitchPort{
+				Site:      sw.SiteName,
+				SwitchMac: sw.Mac,
+				Switch:    name,
+				Port:      idx,
+				Name:      p.Name,
+				Up:        p.Up.Val,
+				Enabled:   p.Enable.Val,
+				Speed:     int(p.Speed.Val),
+				PoE:       p.PortPoe.Val,
+				Uplink:    p.IsUplink.Val,
+				Clients:   clientmap[key{sw.Mac, idx}],
+			})
+		}
+	}
+	return ports
+}
+
+func getPortValue(p *SwitchPort, name string) string {
+	switch name {
+	case PORT_SITE:
+		return p.Site
+	case PORT_SWITCH:
+		return p.Switch
+	case PORT_INDEX:
+		return strconv.Itoa(p.Port)
+	case PORT_NAME:
+		return p.Name
+	case PORT_UP:
+		return strconv.FormatBool(p.Up)
+	case PORT_SPEED:
+		return strconv.Itoa(p.Speed)
+	case PORT_POE:
+		return strconv.FormatBool(p.PoE)
+	case PORT_UPLINK:
+		return strconv.FormatBool(p.Uplink)
+	case PORT_CLIENTS:
+		return strings.Join(p.Clients, " ")
+	default:
+		return "#UNSUPPORTED"
+	}
+}
+
+// portValues returns a rowValue for a list of ports
+func portValues(ports []*SwitchPort) rowValue {
+	return func(row int, field string) string {
+		return getPortValue(ports[row], field)
+	}
+}
+
+// portCells returns a rowCell for a list of ports with
+// numbers and booleans typed for Excel.
+func portCells(ports []*SwitchPort) rowCell {
+	return func(row int, field string) any {
+		p := ports[row]
+		switch field {
+		case PORT_INDEX:
+			return p.Port
+		case PORT_SPEED:
+			return p.Speed
+		case PORT_UP:
+			return p.Up
+		case PORT_POE:
+			return p.PoE
+		case PORT_UPLINK:
+			return p.Uplink
+		default:
+			return getPortValue(p, field)
+		}
+	}
+}
+
+func portTable(out io.Writer, ports []*SwitchPort, fields []string) error {
+	if fields == nil {
+		fields = port_fields
+	}
+	return writeTable(out, fields, len(ports), portValues(ports))
+}
+
+func portJSON(out io.Writer, ports []*SwitchPort, fields []string) error {
+	if fields == nil {
+		fields = port_fields
+	}
+	return writeJSON(out, fields, len(ports), portCells(ports))
+}
+
+func portNDJSON(out io.Writer, ports []*SwitchPort, fields []string) error {
+	if fields == nil {
+		fields = port_fields
+	}
+	return writeNDJSON(out, fields, len(ports), portCells(ports))
+}
+
+func portYAML(out io.Writer, ports []*SwitchPort, fields []string) error {
+	if fields == nil {
+		fields = port_fields
+	}
+	return writeYAML(out, fields, len(ports), portCells(ports), nil)
+}
+
+func portTOML(out io.Writer, ports []*SwitchPort, fields []string) error {
+	if fields == nil {
+		fields = port_fields
+	}
+	return writeTOML(out, "ports", fields, len(ports), portCells(ports), nil)
+}
+
+func portCsv(out io.Writer, ports []*SwitchPort, fields []string) error {
+	if fields == nil {
+		fields = port_fields
+	}
+	return writeCsv(out, fields, len(ports), portValues(ports))
+}
+
+func portExcel(out io.Writer, ports []*SwitchPort, fields []string) error {
+	if fields == nil {
+		fields = port_fields
+	}
+	f := excelize.NewFile()
+	if err := writeSheet(f, f.GetSheetName(0), fields, len(ports), portCells(ports), portStyle); err != nil {
+		return err
+	}
+	return f.Write(out)
+}
+
+func portMarkdown(out io.Writer, ports []*SwitchPort, fields []string) error {
+	if fields == nil {
+		fields = port_fields
+	}
+	return writeMarkdown(out, fields, len(ports), portValues(ports))
+}
+
+func portHTML(out io.Writer, ports []*SwitchPort, fields []string) error {
+	if fields == nil {
+		fields = port_fields
+	}
+	return writeHTML(out, "Ports", fields, len(ports), portValues(ports))
+}
@@ -0,0 +1,270 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/unpoller/unifi"
+)
+
+var (
+	reportCmd         = flag.NewFlagSet("report", flag.ExitOnError)
+	reportDirFlag     = reportCmd.String("dir", filepath.Join("out", "today"), "directory to write the workbooks and summary to")
+	reportStateFlag   = reportCmd.String("state", filepath.Join("out", "report-state.json"), "file remembering the previous run for the changes summary")
+	reportSiteFlag    = reportCmd.String("site", "", "comma separated list of sites to include")
+	reportSubjectFlag = reportCmd.String("subject", "unimac report", "mail subject")
+	reportSMTPFlag    = reportCmd.String("smtp", "", "SMTP server host:port, no mail is sent if empty")
+	reportFromFlag    = reportCmd.String("from", "", "mail sender address")
+	reportToFlag      = reportCmd.String("to", "", "comma separated list of mail recipients")
+)
+
+// reportState is what is remembered between report runs.
+type reportState struct {
+	Time    time.Time `json:"time"`
+	Clients []string  `json:"clients"`
+	Devices []string  `json:"devices"`
+}
+
+// siteSummary are the counts for a single site.
+type siteSummary struct {
+	Site    string
+	Clients int
+	Devices map[string]int
+}
+
+// reportSummary is the data used for the html summary.
+type reportSummary struct {
+	Time        time.Time
+	Since       time.Time
+	Sites       []*siteSummary
+	Clients     int
+	Devices     int
+	NewClients  []*unifi.Client
+	GoneClients []string
+	NewDevices  []*Device
+	GoneDevices []string
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>unimac report</title></head>
+<body style="font-family: sans-serif">
+<h2>unimac report {{.Time.Format "2006-01-02 15:04"}}</h2>
+<p>{{.Clients}} clients and {{.Devices}} devices.</p>
+<table border="1" cellpadding="4" style="border-collapse: collapse">
+<tr><th>Site</th><th>Clients</th><th>Devices</th></tr>
+{{range .Sites}}<tr><td>{{.Site}}</td><td>{{.Clients}}</td><td>{{range $t, $n := .Devices}}{{$t}}: {{$n}} {{end}}</td></tr>
+{{end}}</table>
+{{if .Since.IsZero}}<p>No previous run to compare with.</p>{{else}}
+<h3>Changes since {{.Since.Format "2006-01-02 15:04"}}</h3>
+<p>{{len .NewClients}} new clients, {{len .GoneClients}} gone clients,
+{{len .NewDevices}} new devices and {{len .GoneDevices}} gone devices.</p>
+{{if .NewClients}}<h4>New clients</h4><ul>
+{{range .NewClients}}<li>{{.Mac}} {{.Hostname}} {{.Name}} {{.IP}} ({{.SiteName}})</li>
+{{end}}</ul>{{end}}
+{{if .GoneClients}}<h4>Gone clients</h4><ul>
+{{range .GoneClients}}<li>{{.}}</li>
+{{end}}</ul>{{end}}
+{{if .NewDevices}}<h4>New devices</h4><ul>
+{{range .NewDevices}}<li>{{.Mac}} {{.Type}} {{.Name}} ({{.Site}})</li>
+{{end}}</ul>{{end}}
+{{if .GoneDevices}}<h4>Gone devices</h4><ul>
+{{range .GoneDevices}}<li>{{.}}</li>
+{{end}}</ul>{{end}}
+{{end}}
+</body>
+</html>
+`))
+
+func reportRun(uni *unifi.Unifi, sites []*unifi.Site, args []string) {
+	check(reportCmd.Parse(args))
+	sites = filterSites(sites, splitList(*reportSiteFlag))
+
+	var conf *mailConfig
+	if *reportSMTPFlag != "" {
+		conf = &mailConfig{
+			Server:   *reportSMTPFlag,
+			User:     os.Getenv("UNIMAC_SMTP_USER"),
+			Password: os.Getenv("UNIMAC_SMTP_PASSWORD"),
+			From:     *reportFromFlag,
+			To:       splitList(*reportToFlag),
+		}
+	}
+	check(runReport(uni, sites, *reportDirFlag, *reportStateFlag, *reportSubjectFlag, conf))
+}
+
+// runReport writes clients.xlsx, devices.xlsx and report.html to dir
+// and mails them if conf is not nil.
+func runReport(uni *unifi.Unifi, sites []*unifi.Site, dir, statefile, subject string, conf *mailConfig) error {
+	clients, err := uni.GetClients(sites)
+	if err != nil {
+		return err
+	}
+	unifidevices, err := uni.GetDevices(sites)
+	if err != nil {
+		return err
+	}
+	hydrateClients(clients, unifidevices)
+	devices := buildDevices(unifidevices)
+
+	prev, err := readReportState(statefile)
+	if err != nil {
+		return err
+	}
+	summary := summarize(prev, clients, devices, time.Now())
+	html, attachments, err := renderReport(summary, clients, devices)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, a := range append(attachments, attachment{Name: "report.html", Data: html}) {
+		if err := os.WriteFile(filepath.Join(dir, a.Name), a.Data, 0644); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("wrote report of %d clients and %d devices to %s\n", len(clients), len(devices), dir)
+
+	if conf != nil {
+		msg, err := buildMessage(conf.From, conf.To, subject, string(html), attachments)
+		if err != nil {
+			return err
+		}
+		if err := sendMail(conf, msg); err != nil {
+			return err
+		}
+		fmt.Printf("mailed report to %v\n", conf.To)
+	}
+	return writeReportState(statefile, clients, devices, summary.Time)
+}
+
+// renderReport returns the html summary and the workbooks.
+func renderReport(summary *reportSummary, clients []*unifi.Client, devices []*Device) ([]byte, []attachment, error) {
+	var html, cbuf, dbuf bytes.Buffer
+	if err := reportTemplate.Execute(&html, summary); err != nil {
+		return nil, nil, err
+	}
+	if err := clientExcel(&cbuf, clients, nil); err != nil {
+		return nil, nil, err
+	}
+	if err := devicesExcel(&dbuf, devices, nil); err != nil {
+		return nil, nil, err
+	}
+	return html.Bytes(), []attachment{
+		{Name: "clients.xlsx", Data: cbuf.Bytes()},
+		{Name: "devices.xlsx", Data: dbuf.Bytes()},
+	}, nil
+}
+
+// summarize counts clients and devices per site and compares
+// with the previous run if there was one.
+func summarize(prev *reportState, clients []*unifi.Client, devices []*Device, now time.Time) *reportSummary {
+	summary := &reportSummary{Time: now, Clients: len(clients), Devices: len(devices)}
+	sites := make(map[string]*siteSummary)
+	site := func(name string) *siteSummary {
+		if s, ok := sites[name]; ok {
+			return s
+		}
+		s := &siteSummary{Site: name, Devices: make(map[string]int)}
+		sites[name] = s
+		summary.Sites = append(summary.Sites, s)
+		return s
+	}
+	for _, c := range clients {
+		site(c.SiteName).Clients++
+	}
+	for _, d := range devices {
+		site(d.Site).Devices[d.Type]++
+	}
+	sort.Slice(summary.Sites, func(i, j int) bool {
+		return summary.Sites[i].Site < summary.Sites[j].Site
+	})
+
+	if prev == nil {
+		return summary
+	}
+	summary.Since = prev.Time
+	seen := make(map[string]bool)
+	for _, mac := range prev.Clients {
+		seen[mac] = true
+	}
+	current := make(map[string]bool)
+	for _, c := range clients {
+		current[c.Mac] = true
+		if !seen[c.Mac] {
+			summary.NewClients = append(summary.NewClients, c)
+		}
+	}
+	for _, mac := range prev.Clients {
+		if !current[mac] {
+			summary.GoneClients = append(summary.GoneClients, mac)
+		}
+	}
+
+	seen = make(map[string]bool)
+	for _, mac := range prev.Devices {
+		seen[mac] = true
+	}
+	current = make(map[string]bool)
+	for _, d := range devices {
+		current[d.Mac] = true
+		if !seen[d.Mac] {
+			summary.NewDevices = append(summary.NewDevices, d)
+		}
+	}
+	for _, mac := range prev.Devices {
+		if !current[mac] {
+			summary.GoneDevices = append(summary.GoneDevices, mac)
+		}
+	}
+	return summary
+}
+
+// readReportState returns nil if there is no previous state.
+func readReportState(filename string) (*reportState, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	state := &reportState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", filename, err)
+	}
+	return state, nil
+}
+
+func writeReportState(filename string, clients []*unifi.Client, devices []*Device, now time.Time) error {
+	state := reportState{Time: now}
+	for _, c := range clients {
+		state.Clients = append(state.Clients, c.Mac)
+	}
+	for _, d := range devices {
+		state.Devices = append(state.Devices, d.Mac)
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return encodeJSON(f, state)
+}
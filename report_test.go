@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+package main
+
+import (
+	"bytes"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unpoller/unifi"
+)
+
+// smtpSink accepts a single mail and sends the DATA on the channel.
+func smtpSink(t *testing.T) (string, chan string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := make(chan string, 1)
+	go func() {
+		defer l.Close()
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+		conn := textproto.NewConn(c)
+		defer conn.Close()
+		_ = conn.PrintfLine("220 localhost sink")
+		for {
+			line, err := conn.ReadLine()
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.Fields(line + " ")[0]); cmd {
+			case "DATA":
+				_ = conn.PrintfLine("354 go ahead")
+				data, _ := conn.ReadDotBytes()
+				received <- string(data)
+				_ = conn.PrintfLine("250 queued")
+			case "QUIT":
+				_ = conn.PrintfLine("221 bye")
+				return
+			default:
+				_ = conn.PrintfLine("250 ok")
+			}
+		}
+	}()
+	return l.Addr().String(), received
+}
+
+func Test_sendReport(t *testing.T) {
+	addr, received := smtpSink(t)
+
+	clients := []*unifi.Client{
+		{Mac: "00:11:22:33:44:55", IP: "10.0.0.2", Hostname: "laptop", SiteName: "Office (default)"},
+		{Mac: "66:77:88:99:aa:bb", IP: "10.0.0.3", SiteName: "Office (default)"},
+	}
+	devices := []*Device{{Mac: "f0:9f:c2:00:00:01", Type: "USW", Site: "Office (default)"}}
+	prev := &reportState{
+		Time:    time.Date(2026, 1, 1, 6, 0, 0, 0, time.UTC),
+		Clients: []string{"66:77:88:99:aa:bb", "de:ad:be:ef:00:01"},
+		Devices: []string{"f0:9f:c2:00:00:01"},
+	}
+	summary := summarize(prev, clients, devices, time.Now())
+	if len(summary.NewClients) != 1 || summary.NewClients[0].Mac != "00:11:22:33:44:55" {
+		t.Errorf("new clients = %v", summary.NewClients)
+	}
+	if len(summary.GoneClients) != 1 || summary.GoneClients[0] != "de:ad:be:ef:00:01" {
+		t.Errorf("gone clients = %v", summary.GoneClients)
+	}
+
+	html, attachments, err := renderReport(summary, clients, devices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(html, []byte("laptop")) {
+		t.Errorf("summary does not list the new client:\n%s", html)
+	}
+	conf := &mailConfig{Server: addr, From: "unimac@example.com", To: []string{"helpdesk@example.com"}}
+	msg, err := buildMessage(conf.From, conf.To, "unimac report", string(html), attachments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sendMail(conf, msg); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case data := <-received:
+		for _, want := range []string{"Subject: unimac report", "To: helpdesk@example.com", `filename=clients.xlsx`, `filename=devices.xlsx`, "text/html"} {
+			if !strings.Contains(data, want) {
+				t.Errorf("mail is missing %q", want)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no mail received")
+	}
+}
@@ -0,0 +1,304 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/unpoller/unifi"
+)
+
+var (
+	serveCmd          = flag.NewFlagSet("serve", flag.ExitOnError)
+	serveAddrFlag     = serveCmd.String("addr", ":8080", "address to listen on")
+	serveIntervalFlag = serveCmd.Duration("interval", time.Minute, "how often to poll the controller")
+
+	contentTypes = map[string]string{
+		".json":   "application/json",
+		".ndjson": "application/x-ndjson",
+		".yaml":   "application/yaml",
+		".yml":    "application/yaml",
+		".toml":   "application/toml",
+		".csv":    "text/csv; charset=utf-8",
+		".xlsx":   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		".table":  "text/plain; charset=utf-8",
+		".md":     "text/markdown; charset=utf-8",
+		".html":   "text/html; charset=utf-8",
+	}
+)
+
+// snapshot is the result of one poll of the controller.
+type snapshot struct {
+	Time    time.Time
+	Sites   []*unifi.Site
+	Clients []*unifi.Client
+	Devices []*Device
+	Ports   []*SwitchPort
+}
+
+// poller keeps the latest snapshot from the controller so
+// that requests can be answered without hitting it.
+type poller struct {
+	uni   *unifi.Unifi
+	sites []*unifi.Site
+
+	mu   sync.RWMutex
+	snap *snapshot
+}
+
+func newPoller(uni *unifi.Unifi, sites []*unifi.Site) *poller {
+	return &poller{uni: uni, sites: sites, snap: &snapshot{}}
+}
+
+// takeSnapshot fetches clients and devices for sites.
+func takeSnapshot(uni *unifi.Unifi, sites []*unifi.Site) (*snapshot, error) {
+	clients, err := uni.GetClients(sites)
+	if err != nil {
+		return nil, err
+	}
+	devices, err := uni.GetDevices(sites)
+	if err != nil {
+		return nil, err
+	}
+	hydrateClients(clients, devices)
+
+	return &snapshot{
+		Time:    time.Now(),
+		Sites:   sites,
+		Clients: clients,
+		Devices: buildDevices(devices),
+		Ports:   buildPorts(devices, clients),
+	}, nil
+}
+
+// refresh replaces the current snapshot with a new one.
+func (p *poller) refresh() error {
+	snap, err := takeSnapshot(p.uni, p.sites)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	p.snap = snap
+	p.mu.Unlock()
+	return nil
+}
+
+// run refreshes the snapshot every interval until the program exits.
+func (p *poller) run(interval time.Duration) {
+	for range time.Tick(interval) {
+		if err := p.refresh(); err != nil {
+			log.Println("Error refreshing:", err)
+		}
+	}
+}
+
+func (p *poller) get() *snapshot {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.snap
+}
+
+func serveRun(uni *unifi.Unifi, sites []*unifi.Site) {
+	p := newPoller(uni, sites)
+	check(p.refresh())
+	go p.run(*serveIntervalFlag)
+
+	fmt.Println("Listening on", *serveAddrFlag)
+	log.Fatal(http.ListenAndServe(*serveAddrFlag, newServeMux(p)))
+}
+
+func newServeMux(p *poller) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/clients", p.handleClients)
+	mux.HandleFunc("/api/clients/", p.handleClient)
+	mux.HandleFunc("/api/devices", p.handleDevices)
+	mux.HandleFunc("/api/ports", p.handlePorts)
+	mux.HandleFunc("/api/status", p.handleStatus)
+	mux.Handle("/", webHandler())
+	return mux
+}
+
+// apiQuery holds the common query parameters of the api.
+type apiQuery struct {
+	sites  map[string]bool
+	fields string
+	ext    string
+}
+
+// parseQuery reads site, fields and format from the request.
+// Sites are resolved to the site names used on clients and devices.
+func parseQuery(r *http.Request, snap *snapshot) (*apiQuery, error) {
+	q := r.URL.Query()
+	query := &apiQuery{
+		fields: strings.Join(q["fields"], ","),
+		ext:    ".json",
+	}
+	if format := q.Get("format"); format != "" {
+		query.ext = "." + format
+	}
+	if _, ok := contentTypes[query.ext]; !ok {
+		var formats []string
+		for ext := range contentTypes {
+			formats = append(formats, strings.TrimPrefix(ext, "."))
+		}
+		sort.Strings(formats)
+		return nil, fmt.Errorf("unsupported format '%s', use one of %s", q.Get("format"), strings.Join(formats, ", "))
+	}
+	if names := splitList(strings.Join(q["site"], ",")); len(names) > 0 {
+		query.sites = make(map[string]bool)
+		for _, site := range filterSites(snap.Sites, names) {
+			query.sites[site.SiteName] = true
+		}
+	}
+	return query, nil
+}
+
+// include reports if items from site should be part of the response.
+func (q *apiQuery) include(site string) bool {
+	return q.sites == nil || q.sites[site]
+}
+
+// respond renders into a buffer first so that errors
+// can still be reported with a proper status.
+func (q *apiQuery) respond(w http.ResponseWriter, render func(*bytes.Buffer) error) {
+	var buf bytes.Buffer
+	if err := render(&buf); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentTypes[q.ext])
+	if _, err := buf.WriteTo(w); err != nil {
+		log.Println("Error writing response:", err)
+	}
+}
+
+// handleStatus tells when the snapshot was taken and which sites it covers.
+func (p *poller) handleStatus(w http.ResponseWriter, r *http.Request) {
+	type site struct {
+		Name string `json:"name"`
+		Desc string `json:"desc"`
+	}
+	snap := p.get()
+	status := struct {
+		Updated time.Time `json:"updated"`
+		Sites   []site    `json:"sites"`
+		Clients int       `json:"clients"`
+		Devices int       `json:"devices"`
+	}{
+		Updated: snap.Time,
+		Sites:   []site{},
+		Clients: len(snap.Clients),
+		Devices: len(snap.Devices),
+	}
+	for _, s := range snap.Sites {
+		status.Sites = append(status.Sites, site{Name: s.Name, Desc: s.Desc})
+	}
+	w.Header().Set("Content-Type", contentTypes[".json"])
+	if err := encodeJSON(w, status); err != nil {
+		log.Println("Error writing response:", err)
+	}
+}
+
+func (p *poller) handleClients(w http.ResponseWriter, r *http.Request) {
+	snap := p.get()
+	q, err := parseQuery(r, snap)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	fields, err := parseFields(q.fields, client_fields)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var clients []*unifi.Client
+	for _, c := range snap.Clients {
+		if q.include(c.SiteName) {
+			clients = append(clients, c)
+		}
+	}
+	q.respond(w, func(buf *bytes.Buffer) error {
+		return getClientRender(q.ext)(buf, clients, fields)
+	})
+}
+
+// handleClient returns a single client by MAC address.
+func (p *poller) handleClient(w http.ResponseWriter, r *http.Request) {
+	mac := normalizeMac(strings.TrimPrefix(r.URL.Path, "/api/clients/"))
+	snap := p.get()
+	q, err := parseQuery(r, snap)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	fields, err := parseFields(q.fields, client_fields)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, c := range snap.Clients {
+		if normalizeMac(c.Mac) == mac && q.include(c.SiteName) {
+			q.respond(w, func(buf *bytes.Buffer) error {
+				return getClientRender(q.ext)(buf, []*unifi.Client{c}, fields)
+			})
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
+
+func (p *poller) handleDevices(w http.ResponseWriter, r *http.Request) {
+	snap := p.get()
+	q, err := parseQuery(r, snap)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	fields, err := parseFields(q.fields, device_all_fields)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var devices []*Device
+	for _, d := range snap.Devices {
+		if q.include(d.Site) {
+			devices = append(devices, d)
+		}
+	}
+	q.respond(w, func(buf *bytes.Buffer) error {
+		return getDeviceRender(q.ext)(buf, devices, fields)
+	})
+}
+
+func (p *poller) handlePorts(w http.ResponseWriter, r *http.Request) {
+	snap := p.get()
+	q, err := parseQuery(r, snap)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	fields, err := parseFields(q.fields, port_fields)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var ports []*SwitchPort
+	for _, port := range snap.Ports {
+		if q.include(port.Site) {
+			ports = append(ports, port)
+		}
+	}
+	q.respond(w, func(buf *bytes.Buffer) error {
+		return getPortRender(q.ext)(buf, ports, fields)
+	})
+}
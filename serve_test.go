@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/unpoller/unifi"
+)
+
+func testPoller() *poller {
+	p := newPoller(nil, nil)
+	p.snap = &snapshot{
+		Sites: []*unifi.Site{
+			{Name: "default", Desc: "Office", SiteName: "Office (default)"},
+			{Name: "ab12cd34", Desc: "Warehouse", SiteName: "Warehouse (ab12cd34)"},
+		},
+		Clients: []*unifi.Client{
+			{Mac: "00:11:22:33:44:55", IP: "10.0.0.2", SiteName: "Office (default)"},
+			{Mac: "66:77:88:99:aa:bb", IP: "10.1.0.2", SiteName: "Warehouse (ab12cd34)"},
+		},
+		Devices: []*Device{
+			{Mac: "f0:9f:c2:00:00:01", Type: "USW", Site: "Office (default)"},
+		},
+	}
+	return p
+}
+
+func Test_serveClients(t *testing.T) {
+	mux := newServeMux(testPoller())
+
+	tests := []struct {
+		name   string
+		url    string
+		status int
+		ctype  string
+		want   string
+	}{
+		{"csv", "/api/clients?format=csv&fields=mac,ip", 200, "text/csv", "MAC,IP\n00:11:22:33:44:55,10.0.0.2\n66:77:88:99:aa:bb,10.1.0.2\n"},
+		{"site", "/api/clients?format=csv&fields=MAC&site=warehouse", 200, "text/csv", "MAC\n66:77:88:99:aa:bb\n"},
+		{"single", "/api/clients/00-11-22-33-44-55?format=csv&fields=IP", 200, "text/csv", "IP\n10.0.0.2\n"},
+		{"missing", "/api/clients/00:00:00:00:00:00", 404, "", ""},
+		{"format", "/api/clients?format=doc", 400, "", "unsupported format 'doc', use one of csv, html, json, md, ndjson, table, toml, xlsx, yaml, yml\n"},
+		{"fields", "/api/clients?fields=nope", 400, "", ""},
+		{"yaml", "/api/clients?format=yaml&fields=MAC&site=default", 200, "application/yaml", "- MAC: \"00:11:22:33:44:55\"\n"},
+		{"toml", "/api/devices?format=toml&fields=MAC", 200, "application/toml", "[[devices]]\nMAC = \"f0:9f:c2:00:00:01\"\n"},
+		{"ports", "/api/ports?format=yml&fields=Port", 200, "application/yaml", "[]\n"},
+		{"devices", "/api/devices?format=csv&fields=MAC,Type&site=default", 200, "text/csv", "MAC,Type\nf0:9f:c2:00:00:01,USW\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.url, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.want != "" && rec.Body.String() != tt.want {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.want)
+			}
+			if !strings.HasPrefix(rec.Header().Get("Content-Type"), tt.ctype) {
+				t.Errorf("content type = %s", rec.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func Test_serveWeb(t *testing.T) {
+	mux := newServeMux(testPoller())
+	for _, url := range []string{"/", "/client.html", "/app.js", "/api/status"} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		if rec.Code != 200 {
+			t.Errorf("GET %s = %d, want 200", url, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/unpoller/unifi"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	SITE_NAME    = "Name"
+	SITE_DESC    = "Description"
+	SITE_ID      = "ID"
+	SITE_CLIENTS = "Clients"
+	SITE_DEVICES = "Devices"
+	SITE_USW     = "USW"
+	SITE_UAP     = "UAP"
+	SITE_USG     = "USG"
+	SITE_UXG     = "UXG"
+	SITE_UDM     = "UDM"
+	SITE_WAN     = "WAN"
+	SITE_LAN     = "LAN"
+	SITE_WLAN    = "WLAN"
+	SITE_VPN     = "VPN"
+)
+
+var (
+	sitesCmd       = flag.NewFlagSet("sites", flag.ExitOnError)
+	siteOutputFlag = sitesCmd.String("output", "", "filename to output to. [*.xlsx, *.json, *.ndjson, *.yaml, *.toml, *.csv, *.md, *.html]")
+	siteSiteFlag   = sitesCmd.String("site", "", "comma separated list of sites to include")
+	siteFieldsFlag = sitesCmd.String("fields", "", "comma separated list of fields to output")
+	site_fields    = []string{
+		SITE_NAME, SITE_DESC, SITE_ID, SITE_CLIENTS, SITE_DEVICES,
+		SITE_USW, SITE_UAP, SITE_USG, SITE_UXG, SITE_UDM,
+		SITE_WAN, SITE_LAN, SITE_WLAN, SITE_VPN,
+	}
+)
+
+// siteTypeFields are the fields counting devices of a type.
+var siteTypeFields = map[string]bool{SITE_USW: true, SITE_UAP: true, SITE_USG: true, SITE_UXG: true, SITE_UDM: true}
+
+// siteHealthFields are the fields with the status of a health subsystem.
+var siteHealthFields = map[string]bool{SITE_WAN: true, SITE_LAN: true, SITE_WLAN: true, SITE_VPN: true}
+
+// SiteInfo is a site with counts of what was found on it
+// and the status of the health subsystems by lower case name.
+type SiteInfo struct {
+	Name     string
+	Desc     string
+	ID       string
+	SiteName string
+	Clients  int
+	Devices  int
+	Types    map[string]int
+	Health   map[string]string
+}
+
+// siteRender outputs sites using the selected fields.
+// A nil list of fields means the default ones.
+type siteRender func(io.Writer, []*SiteInfo, []string) error
+
+func generateSites(uni *unifi.Unifi, sites []*unifi.Site) {
+	fields, err := parseFields(*siteFieldsFlag, site_fields)
+	check(err)
+
+	sites = filterSites(sites, splitList(*siteSiteFlag))
+	clients, err := uni.GetClients(sites)
+	if err != nil {
+		log.Fatalln("Error:", err)
+	}
+	devices, err := uni.GetDevices(sites)
+	if err != nil {
+		log.Fatalln("Error:", err)
+	}
+	siteinfo := buildSites(sites, clients, buildDevices(devices))
+
+	ext := ".table"
+	if *siteOutputFlag != "" {
+		ext = filepath.Ext(*siteOutputFlag)
+	}
+	renderer := getSiteRender(ext)
+	if renderer == nil {
+		log.Fatalf("unsupported extension for %s", *siteOutputFlag)
+	}
+	if *siteOutputFlag != "" {
+		f := mustCreateFile(*siteOutputFlag)
+		defer f.Close()
+		err = renderer(f, siteinfo, fields)
+	} else {
+		err = renderer(os.Stdout, siteinfo, fields)
+	}
+	if err != nil {
+		log.Fatalf("error writing '%s': %v", *siteOutputFlag, err)
+	}
+}
+
+// getSiteRender returns the renderer for a file extension
+// or nil if the extension is not supported.
+func getSiteRender(ext string) siteRender {
+	switch ext {
+	case ".xlsx":
+		return siteExcel
+	case ".json":
+		return siteJSON
+	case ".ndjson":
+		return siteNDJSON
+	case ".yaml", ".yml":
+		return siteYAML
+	case ".toml":
+		return siteTOML
+	case ".csv":
+		return siteCsv
+	case ".md":
+		return siteMarkdown
+	case ".html":
+		return siteHTML
+	case ".table":
+		return siteTable
+	}
+	return nil
+}
+
+// buildSites counts clients and devices for each site.
+func buildSites(sites []*unifi.Site, clients []*unifi.Client, devices []*Device) []*SiteInfo {
+	result := make([]*SiteInfo, len(sites))
+	index := make(map[string]*SiteInfo)
+	for i, s := range sites {
+		result[i] = &SiteInfo{
+			Name:     s.Name,
+			Desc:     s.Desc,
+			ID:       s.ID,
+			SiteName: s.SiteName,
+			Types:    make(map[string]int),
+			Health:   make(map[string]string),
+		}
+		for _, h := range s.Health {
+			result[i].Health[strings.ToLower(h.Subsystem)] = h.Status
+		}
+		index[s.SiteName] = result[i]
+	}
+	for _, c := range clients {
+		if s, ok := index[c.SiteName]; ok {
+			s.Clients++
+		}
+	}
+	for _, d := range devices {
+		if s, ok := index[d.Site]; ok {
+			s.Devices++
+			s.Types[d.Type]++
+		}
+	}
+	return result
+}
+
+func getSiteValue(s *SiteInfo, name string) string {
+	switch {
+	case name == SITE_NAME:
+		return s.Name
+	case name == SITE_DESC:
+		return s.Desc
+	case name == SITE_ID:
+		return s.ID
+	case name == SITE_CLIENTS:
+		return strconv.Itoa(s.Clients)
+	case name == SITE_DEVICES:
+		return strconv.Itoa(s.Devices)
+	case siteTypeFields[name]:
+		return strconv.Itoa(s.Types[name])
+	case siteHealthFields[name]:
+		return s.Health[strings.ToLower(name)]
+	default:
+		return "#UNSUPPORTED"
+	}
+}
+
+// siteValues returns a rowValue for a list of sites
+func siteValues(sites []*SiteInfo) rowValue {
+	return func(row int, field string) string {
+		return getSiteValue(sites[row], field)
+	}
+}
+
+// siteCells returns a rowCell for a list of sites with counts as numbers
+// and nil for health subsystems the site does not report.
+func siteCells(sites []*SiteInfo) rowCell {
+	return func(row int, field string) any {
+		s := sites[row]
+		switch {
+		case field == SITE_CLIENTS:
+			return s.Clients
+		case field == SITE_DEVICES:
+			return s.Devices
+		case siteTypeFields[field]:
+			return s.Types[field]
+		case siteHealthFields[field]:
+			if status, ok := s.Health[strings.ToLower(field)]; ok {
+				return status
+			}
+			return nil
+		default:
+			return getSiteValue(s, field)
+		}
+	}
+}
+
+func siteTable(out io.Writer, sites []*SiteInfo, fields []string) error {
+	if fields == nil {
+		fields = site_fields
+	}
+	return writeTable(out, fields, len(sites), siteValues(sites))
+}
+
+func siteJSON(out io.Writer, sites []*SiteInfo, fields []string) error {
+	if fields == nil {
+		fields = site_fields
+	}
+	return writeJSON(out, fields, len(sites), siteCells(sites))
+}
+
+func siteNDJSON(out io.Writer, sites []*SiteInfo, fields []string) error {
+	if fields == nil {
+		fields = site_fields
+	}
+	return writeNDJSON(out, fields, len(sites), siteCells(sites))
+}
+
+func siteYAML(out io.Writer, sites []*SiteInfo, fields []string) error {
+	if fields == nil {
+		fields = site_fields
+	}
+	return writeYAML(out, fields, len(sites), siteCells(sites), nil)
+}
+
+func siteTOML(out io.Writer, sites []*SiteInfo, fields []string) error {
+	if fields == nil {
+		fields = site_fields
+	}
+	return writeTOML(out, "sites", fields, len(sites), siteCells(sites), nil)
+}
+
+func siteCsv(out io.Writer, sites []*SiteInfo, fields []string) error {
+	if fields == nil {
+		fields = site_fields
+	}
+	return writeCsv(out, fields, len(sites), siteValues(sites))
+}
+
+func siteExcel(out io.Writer, sites []*SiteInfo, fields []string) error {
+	if fields == nil {
+		fields = site_fields
+	}
+	f := excelize.NewFile()
+	if err := writeSheet(f, f.GetSheetName(0), fields, len(sites), siteCells(sites), sheetStyle{}); err != nil {
+		return err
+	}
+	return f.Write(out)
+}
+
+func siteMarkdown(out io.Writer, sites []*SiteInfo, fields []string) error {
+	if fields == nil {
+		fields = site_fields
+	}
+	return writeMarkdown(out, fields, len(sites), siteValues(sites))
+}
+
+func siteHTML(out io.Writer, sites []*SiteInfo, fields []string) error {
+	if fields == nil {
+		fields = site_fields
+	}
+	return writeHTML(out, "Sites", fields, len(sites), siteValues(sites))
+}
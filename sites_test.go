@@ -0,0 +1,54 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func Test_buildSites(t *testing.T) {
+	snap := testPoller().snap
+	health := `{"health": [
+		{"subsystem": "wan", "status": "ok"},
+		{"subsystem": "lan", "status": "ok"},
+		{"subsystem": "wlan", "status": "error"},
+		{"subsystem": "www", "status": "ok"}
+	]}`
+	if err := json.Unmarshal([]byte(health), snap.Sites[0]); err != nil {
+		t.Fatal(err)
+	}
+	snap.Devices = append(snap.Devices, &Device{Mac: "f0:9f:c2:00:00:02", Type: "UAP", Site: "Office (default)"})
+	sites := buildSites(snap.Sites, snap.Clients, snap.Devices)
+
+	var buf bytes.Buffer
+	fields := []string{SITE_NAME, SITE_CLIENTS, SITE_DEVICES, SITE_USW, SITE_UAP, SITE_UDM, SITE_WAN, SITE_WLAN, SITE_VPN}
+	if err := siteCsv(&buf, sites, fields); err != nil {
+		t.Fatal(err)
+	}
+	want := `Name,Clients,Devices,USW,UAP,UDM,WAN,WLAN,VPN
+default,1,2,1,1,0,ok,error,
+ab12cd34,1,0,0,0,0,,,
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	buf.Reset()
+	if err := siteNDJSON(&buf, sites[1:], []string{SITE_NAME, SITE_USW, SITE_WAN}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\"Name\":\"ab12cd34\",\"USW\":0,\"WAN\":null}\n"; buf.String() != want {
+		t.Errorf("got %s, want %s", buf.String(), want)
+	}
+}
+
+func Test_getSiteRender(t *testing.T) {
+	for _, ext := range []string{".xlsx", ".json", ".ndjson", ".yaml", ".yml", ".toml", ".csv", ".md", ".html", ".table"} {
+		if getSiteRender(ext) == nil {
+			t.Errorf("no renderer for %s", ext)
+		}
+	}
+}
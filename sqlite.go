@@ -0,0 +1,327 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+
+//go:build sqlite
+
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var (
+	queryCmd        = flag.NewFlagSet("query", flag.ExitOnError)
+	queryFormatFlag = queryCmd.String("format", "table", "output format, table, csv or md")
+)
+
+// sqliteSchema has one table per kind of item. Every row belongs to a run
+// so that exporting to an existing database adds to the history.
+// Sites are referenced by their site name and devices by MAC.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id INTEGER PRIMARY KEY,
+	time TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS sites (
+	run_id INTEGER NOT NULL REFERENCES runs(id),
+	site TEXT NOT NULL,
+	id TEXT,
+	name TEXT,
+	description TEXT,
+	PRIMARY KEY (run_id, site)
+);
+CREATE TABLE IF NOT EXISTS networks (
+	run_id INTEGER NOT NULL REFERENCES runs(id),
+	site TEXT,
+	name TEXT,
+	purpose TEXT,
+	vlan INTEGER,
+	subnet TEXT,
+	dhcp_start TEXT,
+	dhcp_stop TEXT,
+	domain TEXT,
+	FOREIGN KEY (run_id, site) REFERENCES sites(run_id, site)
+);
+CREATE TABLE IF NOT EXISTS devices (
+	run_id INTEGER NOT NULL REFERENCES runs(id),
+	mac TEXT NOT NULL,
+	site TEXT,
+	type TEXT,
+	name TEXT,
+	ip TEXT,
+	config_ip TEXT,
+	state TEXT,
+	note TEXT,
+	uplink_mac TEXT,
+	uplink_port TEXT,
+	PRIMARY KEY (run_id, mac),
+	FOREIGN KEY (run_id, site) REFERENCES sites(run_id, site),
+	FOREIGN KEY (run_id, uplink_mac) REFERENCES devices(run_id, mac)
+);
+CREATE TABLE IF NOT EXISTS ports (
+	run_id INTEGER NOT NULL REFERENCES runs(id),
+	switch_mac TEXT NOT NULL,
+	port INTEGER NOT NULL,
+	name TEXT,
+	up INTEGER,
+	speed INTEGER,
+	poe INTEGER,
+	uplink INTEGER,
+	PRIMARY KEY (run_id, switch_mac, port),
+	FOREIGN KEY (run_id, switch_mac) REFERENCES devices(run_id, mac)
+);
+CREATE TABLE IF NOT EXISTS clients (
+	run_id INTEGER NOT NULL REFERENCES runs(id),
+	mac TEXT NOT NULL,
+	site TEXT,
+	ip TEXT,
+	hostname TEXT,
+	name TEXT,
+	network TEXT,
+	switch_mac TEXT,
+	switch_port INTEGER,
+	ap_mac TEXT,
+	rssi INTEGER,
+	last_seen TEXT,
+	note TEXT,
+	PRIMARY KEY (run_id, mac),
+	FOREIGN KEY (run_id, site) REFERENCES sites(run_id, site),
+	FOREIGN KEY (run_id, switch_mac) REFERENCES devices(run_id, mac),
+	FOREIGN KEY (run_id, ap_mac) REFERENCES devices(run_id, mac)
+);
+CREATE VIEW IF NOT EXISTS latest_clients AS
+	SELECT * FROM clients WHERE run_id = (SELECT max(id) FROM runs);
+CREATE VIEW IF NOT EXISTS latest_devices AS
+	SELECT * FROM devices WHERE run_id = (SELECT max(id) FROM runs);
+`
+
+// openSQLite opens or creates the database filename with foreign keys enforced.
+func openSQLite(filename string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", "file:"+filename+"?_foreign_keys=1")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("creating tables in %s: %w", filename, err)
+	}
+	return db, nil
+}
+
+// writeSQLite adds everything in snap and networks to the database
+// filename as a new run and returns the id of the run.
+func writeSQLite(filename string, snap *snapshot, networks []*NetworkInfo) (int64, error) {
+	db, err := openSQLite(filename)
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	run, err := insertRun(tx, snap, networks)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+	return run, tx.Commit()
+}
+
+func insertRun(tx *sql.Tx, snap *snapshot, networks []*NetworkInfo) (int64, error) {
+	res, err := tx.Exec(`INSERT INTO runs (time) VALUES (?)`, snap.Time.Format(time.RFC3339))
+	if err != nil {
+		return 0, err
+	}
+	run, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	// references to sites and devices missing from the run are
+	// stored as NULL to keep the foreign keys valid
+	sites := make(map[string]bool)
+	for _, s := range snap.Sites {
+		sites[s.SiteName] = true
+		_, err := tx.Exec(`INSERT INTO sites (run_id, site, id, name, description) VALUES (?, ?, ?, ?, ?)`,
+			run, s.SiteName, s.ID, s.Name, s.Desc)
+		if err != nil {
+			return 0, err
+		}
+	}
+	for _, n := range networks {
+		_, err := tx.Exec(`INSERT INTO networks (run_id, site, name, purpose, vlan, subnet, dhcp_start, dhcp_stop, domain)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			run, known(sites, n.Site), n.Name, n.Purpose, n.VLAN, n.Subnet, n.DHCPStart, n.DHCPStop, n.Domain)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	devices := make(map[string]bool)
+	for _, d := range snap.Devices {
+		devices[d.Mac] = true
+	}
+	// an uplink can be a device that comes later in the list so
+	// uplinks are set once all devices of the run are inserted
+	for _, d := range snap.Devices {
+		var upport, configIP any
+		if d.Uplink != nil {
+			upport = d.Uplink.Port
+		}
+		if d.ConfigNetwork != nil {
+			configIP = d.ConfigNetwork.IP
+		}
+		_, err := tx.Exec(`INSERT INTO devices (run_id, mac, site, type, name, ip, config_ip, state, note, uplink_port)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			run, d.Mac, known(sites, d.Site), d.Type, d.Name, d.IP, configIP, d.State, d.Note, upport)
+		if err != nil {
+			return 0, fmt.Errorf("device %s: %w", d.Mac, err)
+		}
+	}
+	for _, d := range snap.Devices {
+		if d.Uplink == nil || !devices[d.Uplink.Mac] {
+			continue
+		}
+		_, err := tx.Exec(`UPDATE devices SET uplink_mac = ? WHERE run_id = ? AND mac = ?`, d.Uplink.Mac, run, d.Mac)
+		if err != nil {
+			return 0, fmt.Errorf("device %s: %w", d.Mac, err)
+		}
+	}
+	for _, p := range snap.Ports {
+		if !devices[p.SwitchMac] {
+			continue
+		}
+		_, err := tx.Exec(`INSERT INTO ports (run_id, switch_mac, port, name, up, speed, poe, uplink) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			run, p.SwitchMac, p.Port, p.Name, p.Up, p.Speed, p.PoE, p.Uplink)
+		if err != nil {
+			return 0, fmt.Errorf("port %s %d: %w", p.SwitchMac, p.Port, err)
+		}
+	}
+
+	// the same client can be reported on more than one site,
+	// only the first one is kept
+	added := make(map[string]bool)
+	for _, c := range snap.Clients {
+		if added[c.Mac] {
+			continue
+		}
+		added[c.Mac] = true
+		var swport, rssi any
+		if c.SwMac != "" {
+			swport = int(c.SwPort.Val)
+		}
+		if c.ApMac != "" {
+			rssi = int(c.Rssi.Val)
+		}
+		_, err := tx.Exec(`INSERT INTO clients (run_id, mac, site, ip, hostname, name, network, switch_mac, switch_port, ap_mac, rssi, last_seen, note)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			run, c.Mac, known(sites, c.SiteName), c.IP, c.Hostname, c.Name, c.Network,
+			known(devices, c.SwMac), swport, known(devices, c.ApMac), rssi,
+			time.Unix(int64(c.LastSeen.Val), 0).Format(time.RFC3339), c.Note)
+		if err != nil {
+			return 0, fmt.Errorf("client %s: %w", c.Mac, err)
+		}
+	}
+	return run, nil
+}
+
+// known returns key if it is in set and nil otherwise.
+func known(set map[string]bool, key string) any {
+	if set[key] {
+		return key
+	}
+	return nil
+}
+
+// queryRun runs a SQL statement against a database written by export.
+func queryRun(args []string) {
+	check(queryCmd.Parse(args))
+	if queryCmd.NArg() != 2 {
+		log.Fatalln("usage: unimac query [-format table|csv|md] file.sqlite \"SELECT ...\"")
+	}
+	filename := queryCmd.Arg(0)
+	if _, err := os.Stat(filename); err != nil {
+		log.Fatalln("Error:", err)
+	}
+	db, err := openSQLite(filename)
+	if err != nil {
+		log.Fatalln("Error:", err)
+	}
+	defer db.Close()
+	check(querySQLite(os.Stdout, db, queryCmd.Arg(1), *queryFormatFlag))
+}
+
+// querySQLite writes the result of query to out as a table, csv or md.
+func querySQLite(out io.Writer, db *sql.DB, query, format string) error {
+	var write func(io.Writer, []string, int, rowValue) error
+	switch format {
+	case "table":
+		write = writeTable
+	case "csv":
+		write = writeCsv
+	case "md":
+		write = writeMarkdown
+	default:
+		return fmt.Errorf("unknown format '%s'", format)
+	}
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	// columns from a join can have the same name, the later
+	// ones get a number so that every column has its own header
+	headers := make([]string, len(columns))
+	index := make(map[string]int, len(columns))
+	for i, c := range columns {
+		header := c
+		for n := 2; ; n++ {
+			if _, ok := index[header]; !ok {
+				break
+			}
+			header = fmt.Sprintf("%s_%d", c, n)
+		}
+		headers[i] = header
+		index[header] = i
+	}
+
+	var records [][]string
+	values := make([]sql.NullString, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		record := make([]string, len(columns))
+		for i, v := range values {
+			record[i] = v.String
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return write(out, headers, len(records), func(row int, field string) string {
+		return strings.TrimSpace(records[row][index[field]])
+	})
+}
@@ -0,0 +1,147 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+
+//go:build sqlite
+
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/unpoller/unifi"
+)
+
+func Test_writeSQLite(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "inventory.sqlite")
+	snap := testPoller().snap
+	snap.Time = time.Date(2026, 3, 4, 5, 6, 0, 0, time.UTC)
+	snap.Clients[0].SwMac = "f0:9f:c2:00:00:01"
+	snap.Clients[1].ApMac = "f0:9f:c2:00:00:99"
+	snap.Devices[0].Uplink = &DevicePort{Mac: "f0:9f:c2:00:00:99", Port: "1"}
+	snap.Ports = []*SwitchPort{{SwitchMac: "f0:9f:c2:00:00:01", Port: 1, Up: true}}
+	networks := []*NetworkInfo{{Site: "Office (default)", Name: "LAN", VLAN: 10}}
+
+	for want := int64(1); want <= 2; want++ {
+		run, err := writeSQLite(filename, snap, networks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if run != want {
+			t.Errorf("run = %d, want %d", run, want)
+		}
+	}
+
+	db, err := openSQLite(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var violations int
+	if err := db.QueryRow(`SELECT count(*) FROM pragma_foreign_key_check`).Scan(&violations); err != nil {
+		t.Fatal(err)
+	}
+	if violations != 0 {
+		t.Errorf("%d foreign key violations", violations)
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`SELECT count(*) AS n FROM clients`, "n\n4\n"},
+		{`SELECT mac, switch_mac, ap_mac FROM latest_clients ORDER BY mac`,
+			"mac,switch_mac,ap_mac\n00:11:22:33:44:55,f0:9f:c2:00:00:01,\n66:77:88:99:aa:bb,,\n"},
+		{`SELECT uplink_mac, uplink_port FROM latest_devices`, "uplink_mac,uplink_port\n,1\n"},
+		{`SELECT n.name, s.description FROM networks n JOIN sites s USING (run_id, site) WHERE run_id = 2`, "name,description\nLAN,Office\n"},
+		{`SELECT time FROM runs WHERE id = 1`, "time\n2026-03-04T05:06:00Z\n"},
+		{`SELECT c.mac, d.mac, d.type FROM clients c JOIN devices d ON d.run_id = c.run_id AND d.mac = c.switch_mac WHERE c.run_id = 1`,
+			"mac,mac_2,type\n00:11:22:33:44:55,f0:9f:c2:00:00:01,USW\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := querySQLite(&buf, db, tt.query, "csv"); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%s\ngot:\n%s\nwant:\n%s", tt.query, got, tt.want)
+		}
+	}
+	if err := querySQLite(&bytes.Buffer{}, db, "SELECT 1", "doc"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func Test_writeSQLite_order(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "inventory.sqlite")
+	snap := testPoller().snap
+	snap.Devices = []*Device{
+		{Mac: "f0:9f:c2:00:00:01", Type: "USW", Site: "Office (default)", Uplink: &DevicePort{Mac: "f0:9f:c2:00:00:02", Port: "8"}},
+		{Mac: "f0:9f:c2:00:00:02", Type: "USW", Site: "Office (default)"},
+	}
+	// the same client reported on both sites
+	snap.Clients = append(snap.Clients, &unifi.Client{Mac: "00:11:22:33:44:55", IP: "10.1.0.3", SiteName: "Warehouse (ab12cd34)"})
+
+	if _, err := writeSQLite(filename, snap, nil); err != nil {
+		t.Fatal(err)
+	}
+	db, err := openSQLite(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`SELECT mac, uplink_mac, uplink_port FROM devices ORDER BY mac`,
+			"mac,uplink_mac,uplink_port\nf0:9f:c2:00:00:01,f0:9f:c2:00:00:02,8\nf0:9f:c2:00:00:02,,\n"},
+		{`SELECT mac, ip FROM clients ORDER BY mac`, "mac,ip\n00:11:22:33:44:55,10.0.0.2\n66:77:88:99:aa:bb,10.1.0.2\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := querySQLite(&buf, db, tt.query, "csv"); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%s\ngot:\n%s\nwant:\n%s", tt.query, got, tt.want)
+		}
+	}
+}
+
+func Test_writeSQLite_udm(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "inventory.sqlite")
+	snap := testPoller().snap
+	// buildDevices adds the UDM last, after the switch behind it
+	snap.Devices = buildDevices(&unifi.Devices{
+		UDMs: []*unifi.UDM{{Mac: "f0:9f:c2:00:00:01", SiteName: "Office (default)",
+			DownlinkTable: []*unifi.DownlinkTable{{Mac: "f0:9f:c2:00:00:02", PortIdx: unifi.FlexInt{Val: 4, Txt: "4"}}}}},
+		USWs: []*unifi.USW{{Mac: "f0:9f:c2:00:00:02", SiteName: "Office (default)",
+			DownlinkTable: []*unifi.DownlinkTable{{Mac: "f0:9f:c2:00:00:03", PortIdx: unifi.FlexInt{Val: 8, Txt: "8"}}}}},
+		UAPs: []*unifi.UAP{{Mac: "f0:9f:c2:00:00:03", SiteName: "Office (default)"}},
+	})
+
+	if _, err := writeSQLite(filename, snap, nil); err != nil {
+		t.Fatal(err)
+	}
+	db, err := openSQLite(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var buf bytes.Buffer
+	if err := querySQLite(&buf, db, `SELECT mac, type, uplink_mac, uplink_port FROM devices ORDER BY mac`, "csv"); err != nil {
+		t.Fatal(err)
+	}
+	want := "mac,type,uplink_mac,uplink_port\n" +
+		"f0:9f:c2:00:00:01,UDM,,\n" +
+		"f0:9f:c2:00:00:02,USW,f0:9f:c2:00:00:01,4\n" +
+		"f0:9f:c2:00:00:03,UAP,f0:9f:c2:00:00:02,8\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,70 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// defaultAnchor is the defined name used in a template
+// when no anchor is given.
+const defaultAnchor = "unimac"
+
+// writeTemplate writes n rows with fields as header into a copy of the
+// workbook template at the anchor given by name, see findAnchor.
+func writeTemplate(out io.Writer, template, name string, fields []string, n int, cell rowCell, style sheetStyle) error {
+	f, err := excelize.OpenFile(template)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	at, err := findAnchor(f, name)
+	if err != nil {
+		return fmt.Errorf("template %s: %w", template, err)
+	}
+	if err := writeAt(f, at, fields, n, cell, style); err != nil {
+		return err
+	}
+	return f.Write(out)
+}
+
+// findAnchor returns where to write in f. The name can be a defined
+// name, a cell like B5 on the first sheet or a reference like Data!B5.
+// Without a name the defined name unimac is used if there is one,
+// otherwise A1 on the first sheet.
+func findAnchor(f *excelize.File, name string) (anchor, error) {
+	ref := name
+	if name == "" {
+		ref = "A1"
+	}
+	for _, dn := range f.GetDefinedName() {
+		if strings.EqualFold(dn.Name, name) || (name == "" && strings.EqualFold(dn.Name, defaultAnchor)) {
+			ref = dn.RefersTo
+			break
+		}
+	}
+
+	at := topLeft(f.GetSheetName(0))
+	ref = strings.TrimPrefix(ref, "=")
+	if i := strings.LastIndex(ref, "!"); i >= 0 {
+		at.sheet = strings.ReplaceAll(strings.Trim(ref[:i], "'"), "''", "'")
+		ref = ref[i+1:]
+	}
+	if i, _ := f.GetSheetIndex(at.sheet); i < 0 {
+		return at, fmt.Errorf("no sheet named '%s'", at.sheet)
+	}
+	// the first cell of a range
+	ref, _, _ = strings.Cut(ref, ":")
+	col, row, err := excelize.CellNameToCoordinates(strings.ReplaceAll(ref, "$", ""))
+	if err != nil {
+		return at, fmt.Errorf("'%s' is not a defined name or cell", name)
+	}
+	at.col, at.row = col, row
+	return at, nil
+}
@@ -0,0 +1,68 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func Test_findAnchor(t *testing.T) {
+	f := excelize.NewFile()
+	if _, err := f.NewSheet("My Data"); err != nil {
+		t.Fatal(err)
+	}
+	check(f.SetDefinedName(&excelize.DefinedName{Name: "unimac", RefersTo: "'My Data'!$C$4:$F$4"}))
+	check(f.SetDefinedName(&excelize.DefinedName{Name: "other", RefersTo: "Sheet1!$B$2"}))
+
+	tests := []struct {
+		name    string
+		want    anchor
+		wantErr bool
+	}{
+		{"", anchor{"My Data", 3, 4}, false},
+		{"other", anchor{"Sheet1", 2, 2}, false},
+		{"D7", anchor{"Sheet1", 4, 7}, false},
+		{"'My Data'!B5", anchor{"My Data", 2, 5}, false},
+		{"Missing!A1", anchor{}, true},
+		{"nothing", anchor{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findAnchor(f, tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("findAnchor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("findAnchor() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_writeTemplate(t *testing.T) {
+	template := filepath.Join(t.TempDir(), "template.xlsx")
+	f := excelize.NewFile()
+	check(f.SetCellValue("Sheet1", "A1", "Port report"))
+	check(f.SetDefinedName(&excelize.DefinedName{Name: "unimac", RefersTo: "Sheet1!$B$3"}))
+	check(f.SaveAs(template))
+
+	ports := []*SwitchPort{{Site: "Office", Switch: "sw-1", Port: 1, Up: true}}
+	var buf bytes.Buffer
+	if err := writeTemplate(&buf, template, "", []string{PORT_SWITCH, PORT_INDEX}, len(ports), portCells(ports), portStyle); err != nil {
+		t.Fatal(err)
+	}
+	out, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for cell, want := range map[string]string{"A1": "Port report", "B3": PORT_SWITCH, "C3": PORT_INDEX, "B4": "sw-1", "C4": "1", "A3": ""} {
+		if got, _ := out.GetCellValue("Sheet1", cell); got != want {
+			t.Errorf("%s = %q, want %q", cell, got, want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/netip"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+// templateFuncs are the helpers available in text templates.
+var templateFuncs = template.FuncMap{
+	"mac":     formatMac,
+	"pad":     padRight,
+	"padLeft": padLeft,
+	"join":    join,
+	"upper":   strings.ToUpper,
+	"lower":   strings.ToLower,
+	"pluck":   pluck,
+	"sortIP":  sortIP,
+}
+
+// writeTextTemplate renders n rows through the text/template in filename.
+// The template is executed with a list of rows where each row is a map
+// from field name to the same text as in table and csv output.
+// Fields that are not identifiers, like Last Seen, are also in the map
+// by their templateKey so that they can be used as .LastSeen.
+func writeTextTemplate(out io.Writer, filename string, fields []string, n int, value rowValue) error {
+	t, err := template.New(filepath.Base(filename)).Funcs(templateFuncs).ParseFiles(filename)
+	if err != nil {
+		return err
+	}
+	rows := make([]map[string]string, n)
+	for row := range rows {
+		rows[row] = make(map[string]string, len(fields))
+		for _, field := range fields {
+			v := value(row, field)
+			rows[row][field] = v
+			rows[row][templateKey(field)] = v
+		}
+	}
+	return t.Execute(out, rows)
+}
+
+// templateKey is field without anything but letters and digits.
+func templateKey(field string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, field)
+}
+
+// formatMac writes mac in one of the styles colon (00:11:22:33:44:55),
+// dash (00-11-22-33-44-55), cisco (0011.2233.4455) or bare (001122334455).
+func formatMac(style, mac string) (string, error) {
+	mac = normalizeMac(mac)
+	if len(mac) != 17 {
+		return mac, nil
+	}
+	bare := strings.ReplaceAll(mac, ":", "")
+	switch style {
+	case "colon":
+		return mac, nil
+	case "dash":
+		return strings.ReplaceAll(mac, ":", "-"), nil
+	case "cisco":
+		return bare[0:4] + "." + bare[4:8] + "." + bare[8:12], nil
+	case "bare":
+		return bare, nil
+	}
+	return "", fmt.Errorf("unknown mac style '%s', use colon, dash, cisco or bare", style)
+}
+
+// padRight pads s with spaces to width characters.
+func padRight(width int, s string) string {
+	if n := width - utf8.RuneCountInString(s); n > 0 {
+		return s + strings.Repeat(" ", n)
+	}
+	return s
+}
+
+// padLeft pads s with leading spaces to width characters.
+func padLeft(width int, s string) string {
+	if n := width - utf8.RuneCountInString(s); n > 0 {
+		return strings.Repeat(" ", n) + s
+	}
+	return s
+}
+
+// join is strings.Join with the separator first
+// so that it can end a pipeline.
+func join(sep string, list []string) string {
+	return strings.Join(list, sep)
+}
+
+// pluck returns the field of every row.
+func pluck(field string, rows []map[string]string) []string {
+	result := make([]string, len(rows))
+	for i, row := range rows {
+		result[i] = row[field]
+	}
+	return result
+}
+
+// sortIP returns a copy of rows sorted by the address in field.
+// Rows without a valid address come last.
+func sortIP(field string, rows []map[string]string) []map[string]string {
+	sorted := make([]map[string]string, len(rows))
+	copy(sorted, rows)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, aerr := netip.ParseAddr(sorted[i][field])
+		b, berr := netip.ParseAddr(sorted[j][field])
+		if aerr != nil || berr != nil {
+			return aerr == nil && berr != nil
+		}
+		return a.Less(b)
+	})
+	return sorted
+}
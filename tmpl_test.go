@@ -0,0 +1,68 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/unpoller/unifi"
+)
+
+func Test_writeTextTemplate(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "desc.tmpl")
+	text := `{{range sortIP "IP" .}}{{pad 10 .Hostname}}|{{mac "cisco" .MAC}}|{{.IP}}|{{.LastSeen}}
+{{end}}{{join "," (pluck "IP" .)}}
+`
+	if err := os.WriteFile(filename, []byte(text), 0644); err != nil {
+		t.Fatal(err)
+	}
+	seen := time.Date(2026, 1, 2, 3, 4, 5, 0, time.Local)
+	clients := []*unifi.Client{
+		{Mac: "00:11:22:33:44:55", Hostname: "b", IP: "10.0.0.10", LastSeen: unifi.FlexInt{Val: float64(seen.Unix())}},
+		{Mac: "00:11:22:33:44:66", Hostname: "c", IP: "", LastSeen: unifi.FlexInt{Val: float64(seen.Unix())}},
+		{Mac: "00:11:22:33:44:77", Hostname: "a", IP: "10.0.0.9", LastSeen: unifi.FlexInt{Val: float64(seen.Add(-time.Hour).Unix())}},
+	}
+	var buf bytes.Buffer
+	err := writeTextTemplate(&buf, filename, client_fields, len(clients), clientValues(clients))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `a         |0011.2233.4477|10.0.0.9|2026-01-02 02:04:05
+b         |0011.2233.4455|10.0.0.10|2026-01-02 03:04:05
+c         |0011.2233.4466||2026-01-02 03:04:05
+10.0.0.10,,10.0.0.9
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func Test_formatMac(t *testing.T) {
+	tests := []struct {
+		style   string
+		want    string
+		wantErr bool
+	}{
+		{"colon", "00:11:22:aa:bb:cc", false},
+		{"dash", "00-11-22-aa-bb-cc", false},
+		{"cisco", "0011.22aa.bbcc", false},
+		{"bare", "001122aabbcc", false},
+		{"other", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.style, func(t *testing.T) {
+			got, err := formatMac(tt.style, "00-11-22-AA-BB-CC")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("formatMac() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("formatMac() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
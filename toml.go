@@ -0,0 +1,95 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// writeTOML outputs n rows as TOML with the same keys and typed values
+// as writeJSON. Without keys every row is a table in the array name,
+// otherwise a table named by the key of the row, see dictKeys.
+// TOML has no null so fields that a row does not have are left out.
+func writeTOML(out io.Writer, name string, fields []string, n int, cell rowCell, keys []string) error {
+	w := bufio.NewWriter(out)
+	for row := 0; row < n; row++ {
+		if row > 0 {
+			w.WriteString("\n")
+		}
+		if keys != nil {
+			fmt.Fprintf(w, "[%s]\n", tomlKey(keys[row]))
+		} else {
+			fmt.Fprintf(w, "[[%s]]\n", tomlKey(name))
+		}
+		for _, field := range fields {
+			v := cell(row, field)
+			if v == nil {
+				continue
+			}
+			value, err := tomlValue(v)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "%s = %s\n", tomlKey(field), value)
+		}
+	}
+	return w.Flush()
+}
+
+// tomlKey returns key bare if it is allowed as a bare key
+// and quoted otherwise.
+func tomlKey(key string) string {
+	if key == "" {
+		return `""`
+	}
+	for _, r := range key {
+		if !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return tomlString(key)
+		}
+	}
+	return key
+}
+
+// tomlString quotes s as a TOML basic string.
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u%04X`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func tomlValue(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return tomlString(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case time.Time:
+		return v.Format(time.RFC3339), nil
+	}
+	return "", fmt.Errorf("can not write %T as toml", v)
+}
@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func Test_writeTOML(t *testing.T) {
+	clients := testYAMLClients()
+	clients[1].Note = "say \"hi\"\n"
+	fields := []string{CLIENT_MAC, CLIENT_SWPORT, CLIENT_NOTE, CLIENT_LASTSEEN}
+	var buf bytes.Buffer
+	if err := clientTOML(&buf, clients, fields); err != nil {
+		t.Fatal(err)
+	}
+	// the unknown switch port of the second client is left out
+	want := `[[clients]]
+MAC = "00:11:22:33:44:55"
+SwPort = 3
+Note = ""
+"Last Seen" = ` + time.Unix(0, 0).Format(time.RFC3339) + `
+
+[[clients]]
+MAC = "66:77:88:99:aa:bb"
+Note = "say \"hi\"\n"
+"Last Seen" = ` + time.Unix(int64(clients[1].LastSeen.Val), 0).Format(time.RFC3339) + `
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	buf.Reset()
+	if err := clientKeyed(".toml", CLIENT_MAC)(&buf, clients, []string{CLIENT_IP}); err != nil {
+		t.Fatal(err)
+	}
+	want = `["00:11:22:33:44:55"]
+IP = "10.0.0.2"
+
+["66:77:88:99:aa:bb"]
+IP = "10.0.0.3"
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func Test_tomlKey(t *testing.T) {
+	tests := map[string]string{
+		"MAC":       "MAC",
+		"up_port-2": "up_port-2",
+		"Last Seen": `"Last Seen"`,
+		"":          `""`,
+		"a\tb":      `"a\tb"`,
+	}
+	for key, want := range tests {
+		if got := tomlKey(key); got != want {
+			t.Errorf("tomlKey(%q) = %s, want %s", key, got, want)
+		}
+	}
+}
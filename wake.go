@@ -0,0 +1,181 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/netip"
+	"strings"
+
+	"github.com/unpoller/unifi"
+)
+
+var (
+	wakeCmd        = flag.NewFlagSet("wake", flag.ExitOnError)
+	wakeSiteFlag   = wakeCmd.String("site", "", "comma separated list of sites to search")
+	wakeHoursFlag  = wakeCmd.Int("hours", 24*30, "include known clients seen within this many hours")
+	wakePortFlag   = wakeCmd.Int("port", 9, "udp port to send the magic packet to")
+	wakeDryRunFlag = wakeCmd.Bool("dry-run", false, "print where the packet would be sent without sending it")
+)
+
+// limitedBroadcast is used when the network of a target is not known.
+var limitedBroadcast = netip.AddrFrom4([4]byte{255, 255, 255, 255})
+
+// wakeTarget is a client to wake and where to send the packet.
+type wakeTarget struct {
+	Mac       string
+	Name      string
+	Site      string
+	Network   string
+	Broadcast netip.Addr
+}
+
+func wakeRun(uni *unifi.Unifi, sites []*unifi.Site, args []string) {
+	check(wakeCmd.Parse(args))
+	if wakeCmd.NArg() != 1 {
+		log.Fatalln("usage: unimac wake [flags] <name|mac|ip>")
+	}
+	sites = filterSites(sites, splitList(*wakeSiteFlag))
+	clients, err := uni.GetClients(sites)
+	if err != nil {
+		log.Fatalln("Error:", err)
+	}
+	users, err := uni.GetUsers(sites, *wakeHoursFlag)
+	if err != nil {
+		log.Fatalln("Error:", err)
+	}
+	networks, err := uni.GetNetworks(sites)
+	if err != nil {
+		log.Fatalln("Error:", err)
+	}
+
+	target, err := resolveWake(wakeCmd.Arg(0), clients, users, networks)
+	if err != nil {
+		log.Fatalln("Error:", err)
+	}
+	addr := netip.AddrPortFrom(target.Broadcast, uint16(*wakePortFlag))
+	if target.Network == "" {
+		fmt.Printf("network of %s is not known, using %s\n", target.Mac, target.Broadcast)
+	}
+	if *wakeDryRunFlag {
+		fmt.Printf("would wake %s (%s) on %s by sending to %s\n", target.Name, target.Mac, target.Site, addr)
+		return
+	}
+	check(sendMagicPacket(target.Mac, addr))
+	fmt.Printf("woke %s (%s) on %s by sending to %s\n", target.Name, target.Mac, target.Site, addr)
+}
+
+// resolveWake finds the single active or known client that has term as
+// MAC, IP, hostname or name. Unlike find only whole values match so
+// that nothing is woken by mistake.
+func resolveWake(term string, clients []*unifi.Client, users []*unifi.User, networks []unifi.Network) (*wakeTarget, error) {
+	matches := func(mac string, values ...string) bool {
+		if normalizeMac(term) == normalizeMac(mac) {
+			return true
+		}
+		for _, v := range values {
+			if v != "" && strings.EqualFold(v, term) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var found []*wakeTarget
+	seen := make(map[string]bool)
+	for _, c := range clients {
+		if matches(c.Mac, c.IP, c.Hostname, c.Name) {
+			seen[c.Mac] = true
+			found = append(found, newWakeTarget(c.Mac, c.Name, c.Hostname, c.SiteName, c.IP, c.NetworkID, networks))
+		}
+	}
+	for _, u := range users {
+		if !seen[u.Mac] && matches(u.Mac, u.FixedIP, u.Hostname, u.Name) {
+			found = append(found, newWakeTarget(u.Mac, u.Name, u.Hostname, u.SiteName, u.FixedIP, u.NetworkID, networks))
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("no client with the name, mac or ip '%s'", term)
+	case 1:
+		return found[0], nil
+	}
+	names := make([]string, len(found))
+	for i, t := range found {
+		names[i] = t.Name + " (" + t.Mac + ")"
+	}
+	return nil, fmt.Errorf("'%s' matches %s, use the mac", term, strings.Join(names, ", "))
+}
+
+// newWakeTarget uses the network with networkID or else the one
+// with the ip to find the broadcast address.
+func newWakeTarget(mac, name, hostname, site, ip, networkID string, networks []unifi.Network) *wakeTarget {
+	t := &wakeTarget{Mac: mac, Name: name, Site: site, Broadcast: limitedBroadcast}
+	if t.Name == "" {
+		t.Name = hostname
+	}
+	if t.Name == "" {
+		t.Name = mac
+	}
+	addr, _ := netip.ParseAddr(ip)
+	for _, n := range networks {
+		subnet, err := netip.ParsePrefix(n.IPSubnet)
+		if err != nil || !subnet.Addr().Is4() {
+			continue
+		}
+		if n.ID == networkID || (networkID == "" && subnet.Masked().Contains(addr)) {
+			t.Network = n.Name
+			t.Broadcast = broadcastAddr(subnet)
+			break
+		}
+	}
+	return t
+}
+
+// broadcastAddr returns the last address of the IPv4 prefix.
+func broadcastAddr(prefix netip.Prefix) netip.Addr {
+	a := prefix.Masked().Addr().As4()
+	for i := range a {
+		bits := prefix.Bits() - i*8
+		switch {
+		case bits <= 0:
+			a[i] = 0xff
+		case bits < 8:
+			a[i] |= 0xff >> bits
+		}
+	}
+	return netip.AddrFrom4(a)
+}
+
+// magicPacket is six bytes of 0xff followed by the mac 16 times.
+func magicPacket(mac string) ([]byte, error) {
+	hw, err := net.ParseMAC(normalizeMac(mac))
+	if err != nil {
+		return nil, err
+	}
+	if len(hw) != 6 {
+		return nil, fmt.Errorf("'%s' is not a 48 bit mac", mac)
+	}
+	return append(bytes.Repeat([]byte{0xff}, 6), bytes.Repeat(hw, 16)...), nil
+}
+
+func sendMagicPacket(mac string, addr netip.AddrPort) error {
+	packet, err := magicPacket(mac)
+	if err != nil {
+		return err
+	}
+	conn, err := net.DialUDP("udp4", nil, net.UDPAddrFromAddrPort(addr))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Write(packet)
+	return err
+}
@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+package main
+
+import (
+	"bytes"
+	"net/netip"
+	"testing"
+
+	"github.com/unpoller/unifi"
+)
+
+func Test_resolveWake(t *testing.T) {
+	networks := []unifi.Network{
+		{ID: "lan", Name: "LAN", IPSubnet: "10.0.0.1/24"},
+		{ID: "iot", Name: "IoT", IPSubnet: "10.0.4.1/22"},
+	}
+	clients := []*unifi.Client{
+		{Mac: "00:11:22:33:44:55", IP: "10.0.0.2", Hostname: "desktop", NetworkID: "lan", SiteName: "Office (default)"},
+		{Mac: "00:11:22:33:44:66", IP: "10.0.0.3", Name: "laptop", NetworkID: "lan"},
+	}
+	users := []*unifi.User{
+		{Mac: "00:11:22:33:44:55", Hostname: "desktop"},
+		{Mac: "00:11:22:33:44:77", Name: "nas", FixedIP: "10.0.5.10"},
+		{Mac: "00:11:22:33:44:88", Name: "Laptop"},
+		{Mac: "00:11:22:33:44:99", Name: "tv"},
+	}
+	tests := []struct {
+		term      string
+		mac       string
+		broadcast string
+	}{
+		{"DESKTOP", "00:11:22:33:44:55", "10.0.0.255"},
+		{"00-11-22-33-44-55", "00:11:22:33:44:55", "10.0.0.255"},
+		{"10.0.0.2", "00:11:22:33:44:55", "10.0.0.255"},
+		{"nas", "00:11:22:33:44:77", "10.0.7.255"},
+		{"tv", "00:11:22:33:44:99", "255.255.255.255"},
+		{"laptop", "", ""},
+		{"desk", "", ""},
+	}
+	for _, tt := range tests {
+		got, err := resolveWake(tt.term, clients, users, networks)
+		if tt.mac == "" {
+			if err == nil {
+				t.Errorf("resolveWake(%s) expected error", tt.term)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("resolveWake(%s): %v", tt.term, err)
+			continue
+		}
+		if got.Mac != tt.mac || got.Broadcast.String() != tt.broadcast {
+			t.Errorf("resolveWake(%s) = %s %s, want %s %s", tt.term, got.Mac, got.Broadcast, tt.mac, tt.broadcast)
+		}
+	}
+}
+
+func Test_broadcastAddr(t *testing.T) {
+	tests := map[string]string{
+		"192.168.1.1/24": "192.168.1.255",
+		"10.0.4.1/22":    "10.0.7.255",
+		"172.16.0.1/12":  "172.31.255.255",
+		"10.0.0.5/32":    "10.0.0.5",
+		"10.0.0.5/0":     "255.255.255.255",
+	}
+	for prefix, want := range tests {
+		if got := broadcastAddr(netip.MustParsePrefix(prefix)).String(); got != want {
+			t.Errorf("broadcastAddr(%s) = %s, want %s", prefix, got, want)
+		}
+	}
+}
+
+func Test_magicPacket(t *testing.T) {
+	packet, err := magicPacket("00-11-22-33-44-55")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packet) != 102 {
+		t.Fatalf("len = %d, want 102", len(packet))
+	}
+	if !bytes.Equal(packet[:6], []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}) {
+		t.Errorf("header = % x", packet[:6])
+	}
+	if !bytes.Equal(packet[96:], []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55}) {
+		t.Errorf("last mac = % x", packet[96:])
+	}
+	if _, err := magicPacket("printer"); err == nil {
+		t.Error("expected error for a name")
+	}
+}
@@ -0,0 +1,202 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/unpoller/unifi"
+)
+
+const (
+	EVENT_JOIN  = "join"
+	EVENT_LEAVE = "leave"
+	EVENT_ROAM  = "roam"
+	EVENT_MOVE  = "move"
+	EVENT_IP    = "ip"
+)
+
+var (
+	watchCmd          = flag.NewFlagSet("watch", flag.ExitOnError)
+	watchIntervalFlag = watchCmd.Duration("interval", 30*time.Second, "how often to poll the controller")
+	watchSiteFlag     = watchCmd.String("site", "", "comma separated list of sites to watch")
+	watchJSONFlag     = watchCmd.Bool("json", false, "output events as newline delimited JSON")
+)
+
+// Event is a change of a client between two polls.
+type Event struct {
+	Time     time.Time `json:"time"`
+	Type     string    `json:"type"`
+	Mac      string    `json:"mac"`
+	Site     string    `json:"site"`
+	Hostname string    `json:"hostname,omitempty"`
+	Name     string    `json:"name,omitempty"`
+	IP       string    `json:"ip,omitempty"`
+	From     string    `json:"from,omitempty"`
+	To       string    `json:"to,omitempty"`
+}
+
+// eventHandler is called for every event found by watch.
+type eventHandler func(Event) error
+
+// clientState is what is tracked of a client between polls.
+type clientState struct {
+	Mac      string
+	Site     string
+	Hostname string
+	Name     string
+	IP       string
+	AP       string
+	Port     string
+}
+
+// stateOf takes the tracked state from a hydrated client.
+func stateOf(c *unifi.Client) clientState {
+	s := clientState{
+		Mac:      c.Mac,
+		Site:     c.SiteName,
+		Hostname: c.Hostname,
+		Name:     c.Name,
+		IP:       c.IP,
+	}
+	if c.ApMac != "" {
+		s.AP = c.ApName
+	} else if c.SwMac != "" {
+		s.Port = fmt.Sprintf("%s port %s", c.SwName, c.SwPort.String())
+	}
+	return s
+}
+
+// location is where the client is connected, AP or switch port.
+func (s clientState) location() string {
+	if s.AP != "" {
+		return s.AP
+	}
+	return s.Port
+}
+
+func (s clientState) event(now time.Time, typ, from, to string) Event {
+	return Event{
+		Time:     now,
+		Type:     typ,
+		Mac:      s.Mac,
+		Site:     s.Site,
+		Hostname: s.Hostname,
+		Name:     s.Name,
+		IP:       s.IP,
+		From:     from,
+		To:       to,
+	}
+}
+
+// diffClients compares two polls and returns the events
+// sorted by MAC address.
+func diffClients(prev, cur map[string]clientState, now time.Time) []Event {
+	var events []Event
+	for mac, c := range cur {
+		p, ok := prev[mac]
+		if !ok {
+			events = append(events, c.event(now, EVENT_JOIN, "", c.location()))
+			continue
+		}
+		if p.AP != "" && c.AP != "" && p.AP != c.AP {
+			events = append(events, c.event(now, EVENT_ROAM, p.AP, c.AP))
+		} else if p.location() != c.location() {
+			events = append(events, c.event(now, EVENT_MOVE, p.location(), c.location()))
+		}
+		if p.IP != c.IP {
+			events = append(events, c.event(now, EVENT_IP, p.IP, c.IP))
+		}
+	}
+	for mac, p := range prev {
+		if _, ok := cur[mac]; !ok {
+			events = append(events, p.event(now, EVENT_LEAVE, p.location(), ""))
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Mac < events[j].Mac
+	})
+	return events
+}
+
+// pollClients fetches and hydrates the clients of sites.
+func pollClients(uni *unifi.Unifi, sites []*unifi.Site) (map[string]clientState, error) {
+	clients, err := uni.GetClients(sites)
+	if err != nil {
+		return nil, err
+	}
+	devices, err := uni.GetDevices(sites)
+	if err != nil {
+		return nil, err
+	}
+	hydrateClients(clients, devices)
+	states := make(map[string]clientState, len(clients))
+	for _, c := range clients {
+		states[c.Mac] = stateOf(c)
+	}
+	return states, nil
+}
+
+// printEvent returns a handler writing events as text or NDJSON.
+func printEvent(out io.Writer, asJSON bool) eventHandler {
+	enc := json.NewEncoder(out)
+	return func(ev Event) error {
+		if asJSON {
+			return enc.Encode(ev)
+		}
+		name := ev.Name
+		if name == "" {
+			name = ev.Hostname
+		}
+		var err error
+		switch ev.Type {
+		case EVENT_JOIN:
+			_, err = fmt.Fprintf(out, "%s %-5s %s %s (%s) on %s\n", ev.Time.Format("2006-01-02 15:04:05"), ev.Type, ev.Mac, name, ev.IP, ev.To)
+		case EVENT_LEAVE:
+			_, err = fmt.Fprintf(out, "%s %-5s %s %s (%s) from %s\n", ev.Time.Format("2006-01-02 15:04:05"), ev.Type, ev.Mac, name, ev.IP, ev.From)
+		default:
+			_, err = fmt.Fprintf(out, "%s %-5s %s %s %s -> %s\n", ev.Time.Format("2006-01-02 15:04:05"), ev.Type, ev.Mac, name, ev.From, ev.To)
+		}
+		return err
+	}
+}
+
+func watchRun(uni *unifi.Unifi, sites []*unifi.Site, args []string) {
+	check(watchCmd.Parse(args))
+	sites = filterSites(sites, splitList(*watchSiteFlag))
+	handlers := append([]eventHandler{printEvent(os.Stdout, *watchJSONFlag)}, notifyHandlers(uni, sites)...)
+	watchClients(uni, sites, *watchIntervalFlag, handlers...)
+}
+
+// watchClients polls every interval and passes the changes
+// since the previous poll to the handlers. It never returns.
+func watchClients(uni *unifi.Unifi, sites []*unifi.Site, interval time.Duration, handlers ...eventHandler) {
+	prev, err := pollClients(uni, sites)
+	check(err)
+	log.Printf("watching %d clients every %s", len(prev), interval)
+
+	for range time.Tick(interval) {
+		cur, err := pollClients(uni, sites)
+		if err != nil {
+			log.Println("Error polling:", err)
+			continue
+		}
+		for _, ev := range diffClients(prev, cur, time.Now()) {
+			for _, h := range handlers {
+				if err := h(ev); err != nil {
+					log.Println("Error handling event:", err)
+				}
+			}
+		}
+		prev = cur
+	}
+}
@@ -0,0 +1,44 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_diffClients(t *testing.T) {
+	prev := map[string]clientState{
+		"aa": {Mac: "aa", IP: "10.0.0.1", AP: "ap-1"},
+		"bb": {Mac: "bb", IP: "10.0.0.2", Port: "sw-1 port 3"},
+		"cc": {Mac: "cc", IP: "10.0.0.3", Port: "sw-1 port 4"},
+		"dd": {Mac: "dd", IP: "10.0.0.4", AP: "ap-1"},
+	}
+	cur := map[string]clientState{
+		"aa": {Mac: "aa", IP: "10.0.0.1", AP: "ap-2"},
+		"bb": {Mac: "bb", IP: "10.0.0.2", Port: "sw-2 port 1"},
+		"cc": {Mac: "cc", IP: "10.0.0.9", Port: "sw-1 port 4"},
+		"ee": {Mac: "ee", IP: "10.0.0.5", AP: "ap-1"},
+	}
+	want := []Event{
+		{Type: EVENT_ROAM, Mac: "aa", From: "ap-1", To: "ap-2"},
+		{Type: EVENT_MOVE, Mac: "bb", From: "sw-1 port 3", To: "sw-2 port 1"},
+		{Type: EVENT_IP, Mac: "cc", From: "10.0.0.3", To: "10.0.0.9"},
+		{Type: EVENT_LEAVE, Mac: "dd", From: "ap-1"},
+		{Type: EVENT_JOIN, Mac: "ee", To: "ap-1"},
+	}
+	got := diffClients(prev, cur, time.Now())
+	if len(got) != len(want) {
+		t.Fatalf("got %d events %v, want %d", len(got), got, len(want))
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Type != w.Type || g.Mac != w.Mac || g.From != w.From || g.To != w.To {
+			t.Errorf("event %d = %s %s %s->%s, want %s %s %s->%s", i, g.Type, g.Mac, g.From, g.To, w.Type, w.Mac, w.From, w.To)
+		}
+	}
+	if got := diffClients(cur, cur, time.Now()); len(got) != 0 {
+		t.Errorf("no changes gave %v", got)
+	}
+}
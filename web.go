@@ -0,0 +1,22 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// webFS holds the browser UI served by serve.
+//
+//go:embed web
+var webFS embed.FS
+
+func webHandler() http.Handler {
+	sub, err := fs.Sub(webFS, "web")
+	check(err)
+	return http.FileServer(http.FS(sub))
+}
@@ -0,0 +1,160 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+var unimac = (function () {
+  "use strict";
+
+  var views = {
+    clients: {
+      url: "api/clients",
+      fields: ["MAC", "IP", "Hostname", "Name", "Site", "Network", "Switch", "SwPort", "AP", "RSSI", "Last Seen", "Note"],
+      link: function (row) { return "client.html?mac=" + encodeURIComponent(row.MAC); }
+    },
+    devices: {
+      url: "api/devices",
+      fields: ["MAC", "Type", "Site", "IP", "Name", "Uplink", "UpPort", "ConfigIP", "Note", "State"]
+    }
+  };
+
+  var state = { view: null, rows: [], sort: null, desc: false };
+
+  function $(id) { return document.getElementById(id); }
+
+  function query(params) {
+    return Object.keys(params).filter(function (k) { return params[k]; }).map(function (k) {
+      return k + "=" + encodeURIComponent(params[k]);
+    }).join("&");
+  }
+
+  function get(url) {
+    return fetch(url).then(function (res) {
+      if (!res.ok) { throw new Error(res.status + " " + res.statusText); }
+      return res.json();
+    });
+  }
+
+  function status() {
+    return get("api/status").then(function (s) {
+      $("updated").textContent = "Updated " + new Date(s.updated).toLocaleString();
+      return s;
+    });
+  }
+
+  function cell(tag, text) {
+    var el = document.createElement(tag);
+    el.textContent = text;
+    return el;
+  }
+
+  // text formats a typed JSON value for display.
+  function text(v) {
+    if (v === null || v === undefined) { return ""; }
+    if (typeof v === "string" && /^\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d/.test(v)) {
+      return v.slice(0, 10) + " " + v.slice(11, 19);
+    }
+    return String(v);
+  }
+
+  function render() {
+    var view = views[state.view];
+    var search = $("search").value.toLowerCase();
+    var rows = state.rows.filter(function (row) {
+      return !search || view.fields.some(function (f) {
+        return text(row[f]).toLowerCase().indexOf(search) >= 0;
+      });
+    });
+    if (state.sort) {
+      rows.sort(function (a, b) {
+        var x = text(a[state.sort]), y = text(b[state.sort]);
+        var c = x.localeCompare(y, undefined, { numeric: true });
+        return state.desc ? -c : c;
+      });
+    }
+
+    var head = document.createElement("tr");
+    view.fields.forEach(function (f) {
+      var th = cell("th", f);
+      if (f === state.sort) { th.className = state.desc ? "desc" : "asc"; }
+      th.onclick = function () {
+        state.desc = state.sort === f ? !state.desc : false;
+        state.sort = f;
+        render();
+      };
+      head.appendChild(th);
+    });
+    var thead = $("list").tHead;
+    thead.replaceChildren(head);
+
+    var tbody = $("list").tBodies[0];
+    tbody.replaceChildren.apply(tbody, rows.map(function (row) {
+      var tr = document.createElement("tr");
+      view.fields.forEach(function (f, i) {
+        var td = cell("td", text(row[f]));
+        if (i === 0 && view.link) {
+          var a = cell("a", text(row[f]));
+          a.href = view.link(row);
+          td.replaceChildren(a);
+        }
+        tr.appendChild(td);
+      });
+      return tr;
+    }));
+    $("count").textContent = rows.length + " of " + state.rows.length;
+  }
+
+  function load() {
+    var name = location.hash.replace("#", "") || "clients";
+    var view = views[name];
+    if (!view) { return; }
+    state.view = name;
+    state.sort = null;
+    document.querySelectorAll("nav a").forEach(function (a) {
+      a.className = a.dataset.view === name ? "active" : "";
+    });
+    var params = { site: $("site").value, fields: view.fields.join(",") };
+    $("download-xlsx").href = view.url + "?" + query(Object.assign({ format: "xlsx" }, params));
+    $("download-csv").href = view.url + "?" + query(Object.assign({ format: "csv" }, params));
+    get(view.url + "?" + query(params)).then(function (rows) {
+      state.rows = rows || [];
+      render();
+    });
+  }
+
+  function list() {
+    status().then(function (s) {
+      (s.sites || []).forEach(function (site) {
+        var opt = cell("option", site.desc);
+        opt.value = site.name;
+        $("site").appendChild(opt);
+      });
+    });
+    $("search").oninput = render;
+    $("site").onchange = load;
+    window.onhashchange = load;
+    load();
+  }
+
+  function client() {
+    var mac = new URLSearchParams(location.search).get("mac");
+    var fields = views.clients.fields;
+    status();
+    get("api/clients/" + encodeURIComponent(mac) + "?" + query({ fields: fields.join(",") })).then(function (rows) {
+      var row = rows[0];
+      $("title").textContent = row.Name || row.Hostname || row.MAC;
+      if (row.Switch) {
+        $("location").textContent = "Wired on " + row.Switch + " port " + row.SwPort;
+      } else if (row.AP) {
+        $("location").textContent = "Wireless on " + row.AP + " (RSSI " + row.RSSI + ")";
+      }
+      var dl = $("detail");
+      fields.forEach(function (f) {
+        dl.appendChild(cell("dt", f));
+        dl.appendChild(cell("dd", text(row[f])));
+      });
+    }).catch(function (err) {
+      $("title").textContent = mac + ": " + err.message;
+    });
+  }
+
+  return { list: list, client: client };
+}());
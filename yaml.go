@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// writeYAML outputs n rows as YAML with the same keys and typed values
+// as writeJSON. Without keys the rows are a list, otherwise a mapping
+// from the key of each row, see dictKeys.
+func writeYAML(out io.Writer, fields []string, n int, cell rowCell, keys []string) error {
+	doc := &yaml.Node{Kind: yaml.SequenceNode}
+	if keys != nil {
+		doc.Kind = yaml.MappingNode
+	}
+	for row := 0; row < n; row++ {
+		record := &yaml.Node{Kind: yaml.MappingNode}
+		for _, field := range fields {
+			value, err := yamlValue(cell(row, field))
+			if err != nil {
+				return err
+			}
+			record.Content = append(record.Content, yamlString(field), value)
+		}
+		if keys != nil {
+			// quoted as yaml.v3 leaves keys like 00:11:22:33:44:55
+			// plain, which YAML 1.1 parsers read as a number
+			key := yamlString(keys[row])
+			key.Style = yaml.DoubleQuotedStyle
+			doc.Content = append(doc.Content, key)
+		}
+		doc.Content = append(doc.Content, record)
+	}
+	enc := yaml.NewEncoder(out)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func yamlString(s string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s}
+}
+
+// yamlValue is v as a node where nil, for a value the
+// row does not have, becomes null.
+func yamlValue(v any) (*yaml.Node, error) {
+	if v == nil {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	}
+	node := &yaml.Node{}
+	if err := node.Encode(v); err != nil {
+		return nil, fmt.Errorf("encoding %v: %w", v, err)
+	}
+	return node, nil
+}
+
+// dictKeys returns the text of field for every row to be used as
+// keys of a dictionary. Every row must have a key and no two
+// rows can have the same.
+func dictKeys(n int, field string, value rowValue) ([]string, error) {
+	keys := make([]string, n)
+	seen := make(map[string]bool, n)
+	for row := range keys {
+		key := value(row, field)
+		if key == "" {
+			return nil, fmt.Errorf("row %d has no %s to use as key", row+1, field)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("%s '%s' is used by more than one row", field, key)
+		}
+		seen[key] = true
+		keys[row] = key
+	}
+	return keys, nil
+}
@@ -0,0 +1,73 @@
+// SPDX-FileCopyrightText: 2026 Peter Magnusson <me@kmpm.se>
+//
+// SPDX-License-Identifier: MIT
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/unpoller/unifi"
+)
+
+func testYAMLClients() []*unifi.Client {
+	return []*unifi.Client{
+		{Mac: "00:11:22:33:44:55", IP: "10.0.0.2", SwMac: "f0:9f:c2:00:00:01", SwPort: unifi.FlexInt{Val: 3}},
+		{Mac: "66:77:88:99:aa:bb", IP: "10.0.0.3", Name: "yes", LastSeen: unifi.FlexInt{Val: float64(time.Date(2026, 3, 4, 5, 6, 0, 0, time.UTC).Unix())}},
+	}
+}
+
+func Test_writeYAML(t *testing.T) {
+	clients := testYAMLClients()
+	fields := []string{CLIENT_MAC, CLIENT_NAME, CLIENT_SWPORT}
+	var buf bytes.Buffer
+	if err := clientYAML(&buf, clients, fields); err != nil {
+		t.Fatal(err)
+	}
+	// digits and colons would be a number in YAML 1.1
+	want := `- MAC: "00:11:22:33:44:55"
+  Name: ""
+  SwPort: 3
+- MAC: 66:77:88:99:aa:bb
+  Name: "yes"
+  SwPort: null
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	buf.Reset()
+	if err := clientKeyed(".yml", CLIENT_MAC)(&buf, clients, []string{CLIENT_IP}); err != nil {
+		t.Fatal(err)
+	}
+	want = `"00:11:22:33:44:55":
+  IP: 10.0.0.2
+"66:77:88:99:aa:bb":
+  IP: 10.0.0.3
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if clientKeyed(".json", CLIENT_MAC) != nil {
+		t.Error("expected no keyed renderer for json")
+	}
+}
+
+func Test_dictKeys(t *testing.T) {
+	values := clientValues(testYAMLClients())
+	if _, err := dictKeys(2, CLIENT_NAME, values); err == nil {
+		t.Error("expected error for a row without key")
+	}
+	if _, err := dictKeys(2, CLIENT_HOSTNAME, values); err == nil {
+		t.Error("expected error for empty keys")
+	}
+	keys, err := dictKeys(2, CLIENT_IP, values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys[0] != "10.0.0.2" || keys[1] != "10.0.0.3" {
+		t.Errorf("keys = %v", keys)
+	}
+}